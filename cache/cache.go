@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+// Cache keeps the most popular searches in memory, with an optional storage backend as a second, shared tier
+type Cache struct {
+	mu      sync.Mutex
+	maxSize int
+	entries map[string]*entry
+	//increments on every use, used to find the least recently used entry when hits are equal
+	clock  uint64
+	second storage.Store
+	stats  structures.CacheStats
+}
+
+// one cached search in the memory tier
+type entry struct {
+	structures.CacheEntry
+	lastUsed uint64
+}
+
+// the search cache used by the service, memory only until the server gives it a second tier
+var Searches = New(structures.MAXCACHESIZE, nil)
+
+// creates a cache holding at most maxSize searches in memory, second can be nil to only use memory
+func New(maxSize int, second storage.Store) *Cache {
+	return &Cache{
+		maxSize: maxSize,
+		entries: make(map[string]*entry),
+		second:  second,
+	}
+}
+
+// gets cached data, first from memory and then from the second tier, nil if neither has it
+func (c *Cache) Get(ctx context.Context, cacheKey string) []structures.DataEntry {
+	cacheKey = strings.ToLower(cacheKey)
+
+	c.mu.Lock()
+	if e, ok := c.entries[cacheKey]; ok {
+		c.clock++
+		e.lastUsed = c.clock
+		e.Hits++
+		c.stats.Memory.Hits++
+		//copied since callers are allowed to sort the result
+		data := append([]structures.DataEntry(nil), e.Data...)
+		c.mu.Unlock()
+		return data
+	}
+	c.stats.Memory.Misses++
+	c.mu.Unlock()
+
+	if c.second == nil {
+		return nil
+	}
+
+	//the lock is not held while waiting for the second tier
+	stored, err := c.second.GetCachedData(ctx, cacheKey)
+	if err != nil {
+		log.Printf("Error getting cached data: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stored == nil {
+		c.stats.Store.Misses++
+		return nil
+	}
+	c.stats.Store.Hits++
+	//promoted with the hits counted by the second tier, so popular searches are kept in memory
+	c.insert(*stored)
+	return append([]structures.DataEntry(nil), stored.Data...)
+}
+
+// places the data in memory and in the second tier
+func (c *Cache) Set(ctx context.Context, cacheKey string, data []structures.DataEntry) {
+	cacheKey = strings.ToLower(cacheKey)
+	copied := append([]structures.DataEntry(nil), data...)
+
+	c.mu.Lock()
+	if e, ok := c.entries[cacheKey]; ok {
+		e.Data = copied
+		e.Timestamp = time.Now()
+	} else {
+		c.insert(structures.CacheEntry{Key: cacheKey, Data: copied, Timestamp: time.Now(), Hits: 1})
+	}
+	c.mu.Unlock()
+
+	if c.second != nil {
+		err := c.second.SetCachedData(ctx, cacheKey, data)
+		if err != nil {
+			log.Printf("Error setting cached data: %v", err)
+		}
+	}
+}
+
+// inserts into memory, replacing the entry with the least hits if the cache is full, must hold the lock
+func (c *Cache) insert(cached structures.CacheEntry) {
+	if c.maxSize <= 0 {
+		return
+	}
+	if _, ok := c.entries[cached.Key]; !ok && len(c.entries) >= c.maxSize {
+		c.evict()
+	}
+	if cached.Timestamp.IsZero() {
+		cached.Timestamp = time.Now()
+	}
+	c.clock++
+	c.entries[cached.Key] = &entry{CacheEntry: cached, lastUsed: c.clock}
+}
+
+// removes the entry with the least hits, the least recently used of them if several are equal
+func (c *Cache) evict() {
+	var victim *entry
+	for _, e := range c.entries {
+		if victim == nil || e.Hits < victim.Hits || (e.Hits == victim.Hits && e.lastUsed < victim.lastUsed) {
+			victim = e
+		}
+	}
+	if victim != nil {
+		delete(c.entries, victim.Key)
+		c.stats.Evictions++
+	}
+}
+
+// deletes entries in memory older than a specific treshold, the second tier purges its own
+func (c *Cache) PurgeOldEntries(daysThreshold int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limit := time.Now().AddDate(0, 0, -daysThreshold)
+	deleted := 0
+	for key, e := range c.entries {
+		if e.Timestamp.Before(limit) {
+			delete(c.entries, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// deletes every entry in memory, used when the data has been replaced. Entries in the second tier
+// are left for the other instances and expire with PurgeOldCacheEntries
+func (c *Cache) Invalidate() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deleted := len(c.entries)
+	c.entries = make(map[string]*entry)
+	return deleted
+}
+
+// returns a copy of the counters
+func (c *Cache) Stats() structures.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = len(c.entries)
+	stats.MaxSize = c.maxSize
+	return stats
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+func entries(country string) []structures.DataEntry {
+	return []structures.DataEntry{{Country: country, Year: 2021}}
+}
+
+func TestEvictsLeastHits(t *testing.T) {
+	ctx := context.Background()
+	c := New(2, nil)
+
+	c.Set(ctx, "norway", entries("Norway"))
+	c.Set(ctx, "sweden", entries("Sweden"))
+	//norway gets more hits than sweden, so sweden is replaced
+	c.Get(ctx, "norway")
+	c.Set(ctx, "denmark", entries("Denmark"))
+
+	assert.NotNil(t, c.Get(ctx, "norway"))
+	assert.NotNil(t, c.Get(ctx, "denmark"))
+	assert.Nil(t, c.Get(ctx, "sweden"))
+	assert.Equal(t, int64(1), c.Stats().Evictions)
+}
+
+func TestEvictsLeastRecentlyUsedOnEqualHits(t *testing.T) {
+	ctx := context.Background()
+	c := New(2, nil)
+
+	c.Set(ctx, "norway", entries("Norway"))
+	c.Set(ctx, "sweden", entries("Sweden"))
+	c.Get(ctx, "norway")
+	c.Get(ctx, "sweden")
+	//both have two hits, norway was used longest ago
+	c.Set(ctx, "denmark", entries("Denmark"))
+
+	assert.Nil(t, c.Get(ctx, "norway"))
+	assert.NotNil(t, c.Get(ctx, "sweden"))
+}
+
+func TestSecondTier(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	c := New(1, store)
+
+	c.Set(ctx, "Norway", entries("Norway"))
+	//the second tier has it even after it is evicted from memory
+	c.Set(ctx, "sweden", entries("Sweden"))
+	assert.Equal(t, entries("Norway"), c.Get(ctx, "norway"))
+
+	//a fresh cache sharing the store finds it in the second tier and then in memory
+	other := New(1, store)
+	assert.Equal(t, entries("Sweden"), other.Get(ctx, "sweden"))
+	assert.Equal(t, entries("Sweden"), other.Get(ctx, "sweden"))
+	assert.Nil(t, other.Get(ctx, "finland"))
+
+	stats := other.Stats()
+	assert.Equal(t, structures.CacheTierStats{Hits: 1, Misses: 2}, stats.Memory)
+	assert.Equal(t, structures.CacheTierStats{Hits: 1, Misses: 1}, stats.Store)
+	assert.Equal(t, 1, stats.Size)
+}
+
+func TestPromotionKeepsStoredHits(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	//a popular search counted by another instance
+	store.SetCachedData(ctx, "norway", entries("Norway"))
+	for i := 0; i < 10; i++ {
+		store.GetCachedData(ctx, "norway")
+	}
+
+	c := New(2, store)
+	c.Get(ctx, "norway")
+	for i := 0; i < 3; i++ {
+		c.Set(ctx, fmt.Sprintf("country%d", i), entries("Other"))
+	}
+	assert.NotNil(t, c.Get(ctx, "norway"))
+}
+
+func TestResultIsCopied(t *testing.T) {
+	ctx := context.Background()
+	c := New(1, nil)
+	c.Set(ctx, "norway", entries("Norway"))
+
+	data := c.Get(ctx, "norway")
+	data[0].Country = "Changed"
+	assert.Equal(t, entries("Norway"), c.Get(ctx, "norway"))
+}
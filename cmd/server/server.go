@@ -6,20 +6,80 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
+	"groupXX/cache"
+	"groupXX/countries"
+	"groupXX/functions"
 	"groupXX/handlers"
+	"groupXX/storage"
 	"groupXX/structures"
-	"groupXX/firebase"
 )
 
 func main() {
-	// Create a Firestore client
+	// Open the storage backend chosen by STORAGE_BACKEND (firestore, memory or bolt)
 	ctx := context.Background()
-	client, err := firebase.CreateFirestoreClient(ctx)
+	store, err := storage.OpenFromEnv(ctx)
 	if err != nil {
-		log.Fatalf("Error creating Firestore client: %v", err)
+		log.Fatalf("Error opening storage: %v", err)
+	}
+	defer store.Close()
+	storage.DB = store
+
+	// The search cache uses the storage as a shared second tier unless CACHE_SECOND_TIER=false
+	if secondTier, err := strconv.ParseBool(os.Getenv("CACHE_SECOND_TIER")); err == nil && !secondTier {
+		cache.Searches = cache.New(structures.MAXCACHESIZE, nil)
+	} else {
+		cache.Searches = cache.New(structures.MAXCACHESIZE, storage.DB)
+	}
+
+	// Load the data from ENERGY_DATA_PATH, which also checks if any countries have crossed the threshold of a webhook
+	if path := os.Getenv("ENERGY_DATA_PATH"); path != "" {
+		functions.DataPath = path
+	}
+	if _, err := functions.Reload(ctx); err != nil {
+		log.Fatalf("Error loading data: %v", err)
+	}
+
+	// Reload the data on SIGHUP
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			if _, err := functions.Reload(ctx); err != nil {
+				log.Printf("Error reloading data, keeping the previous data: %v", err)
+			}
+		}
+	}()
+
+	// Reload the data when the file changes, checked every DATA_POLL_INTERVAL (0 turns it off)
+	pollInterval := structures.DATAPOLLINTERVAL
+	if intervalStr := os.Getenv("DATA_POLL_INTERVAL"); intervalStr != "" {
+		pollInterval, err = time.ParseDuration(intervalStr)
+		if err != nil {
+			log.Fatalf("Error parsing DATA_POLL_INTERVAL: %v", err)
+		}
+	}
+	if pollInterval > 0 {
+		go functions.WatchData(pollInterval, nil)
+	}
+
+	// The countries API is at COUNTRIES_API_URL, which tests and other deployments can point somewhere else
+	if url := os.Getenv("COUNTRIES_API_URL"); url != "" {
+		countries.DefaultClient = countries.NewBreaker(countries.NewHTTPClient(url))
+	}
+
+	// Refresh the local countries and their borders from the countries API every COUNTRIES_REFRESH_INTERVAL,
+	// off by default so the service doesn't depend on the API
+	if intervalStr := os.Getenv("COUNTRIES_REFRESH_INTERVAL"); intervalStr != "" {
+		refreshInterval, err := time.ParseDuration(intervalStr)
+		if err != nil || refreshInterval <= 0 {
+			log.Fatalf("Error parsing COUNTRIES_REFRESH_INTERVAL: %v", intervalStr)
+		}
+		go countries.WatchCountries(countries.DefaultClient, refreshInterval, nil)
 	}
-	defer client.Close()
 
 	// Create a ticker to purge old cache entries every daysThreshold days
 	purgeInterval := time.Duration(structures.DAYSTHRESHOLD) * 24 * time.Hour
@@ -28,7 +88,13 @@ func main() {
 	// Run the PurgeOldCacheEntries function in the background
 	go func() {
 		for range ticker.C {
-			firebase.PurgeOldCacheEntries(ctx, client, structures.DAYSTHRESHOLD)
+			cache.Searches.PurgeOldEntries(structures.DAYSTHRESHOLD)
+			deleted, err := storage.DB.PurgeOldCacheEntries(ctx, structures.DAYSTHRESHOLD)
+			if err != nil {
+				log.Printf("Error purging old cache entries: %v", err)
+			} else {
+				log.Printf("Treshold met, deleted %d cache entries", deleted)
+			}
 		}
 	}()
 	
@@ -47,6 +113,17 @@ func main() {
 	http.HandleFunc(structures.RENEWABLEHISTORY_PATH, handlers.HistoryHandler)
 	http.HandleFunc(structures.NOTIFICATIONS_PATH, handlers.NotificationsHandler)
 	http.HandleFunc(structures.STATUS_PATH, handlers.StatusHandler)
+	http.HandleFunc(structures.STATS_PATH, handlers.StatsHandler)
+	http.HandleFunc(structures.SEARCH_PATH, handlers.SearchHandler)
+	http.HandleFunc(structures.RELOAD_PATH, handlers.ReloadHandler)
+	http.HandleFunc(structures.METRICS_PATH, handlers.MetricsHandler)
+	http.HandleFunc(structures.MIX_PATH, handlers.MixHandler)
+	http.HandleFunc(structures.REGIONS_PATH, handlers.RegionsHandler)
+	http.HandleFunc(structures.AGGREGATE_PATH, handlers.AggregateHandler)
+	http.HandleFunc(structures.RANK_PATH, handlers.RankHandler)
+	http.HandleFunc(structures.TREND_PATH, handlers.TrendHandler)
+	http.HandleFunc(structures.FORECAST_PATH, handlers.ForecastHandler)
+	http.HandleFunc(structures.COMPARE_PATH, handlers.CompareHandler)
 	http.HandleFunc(structures.INFO_PATH, handlers.InfoHandler)
 
 	//starts server
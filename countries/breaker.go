@@ -0,0 +1,190 @@
+package countries
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"groupXX/structures"
+)
+
+// Breaker is a circuit breaker in front of a countries client. After BREAKERFAILURES failures in a row it opens
+// and answers without calling the client for BREAKERCOOLDOWN, then lets one request through to check if the
+// client works again. While the client fails, a lookup by name or code is answered with the last good answer
+// for the same lookup, or from the local countries if there is none
+type Breaker struct {
+	client       Client
+	failureLimit int
+	cooldown     time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openedAt  time.Time
+	lastError string
+	//if the request which checks if the client works again is running
+	trial       bool
+	staleServed int64
+	//lookup -> last good answer
+	lastGood map[string][]structures.Country
+}
+
+// puts a breaker with the default limits in front of the client
+func NewBreaker(client Client) *Breaker {
+	return &Breaker{
+		client:       client,
+		failureLimit: structures.BREAKERFAILURES,
+		cooldown:     structures.BREAKERCOOLDOWN,
+		now:          time.Now,
+		state:        structures.BREAKER_CLOSED,
+		lastGood:     make(map[string][]structures.Country),
+	}
+}
+
+func (b *Breaker) ByName(ctx context.Context, name string) ([]structures.Country, error) {
+	return b.call(ctx, "name:"+Normalise(name), func(ctx context.Context) ([]structures.Country, error) {
+		return b.client.ByName(ctx, name)
+	}, name)
+}
+
+func (b *Breaker) ByCode(ctx context.Context, code string) ([]structures.Country, error) {
+	return b.call(ctx, "code:"+Normalise(code), func(ctx context.Context) ([]structures.Country, error) {
+		return b.client.ByCode(ctx, code)
+	}, code)
+}
+
+// every country falls back to the last good answer only, as the local countries are what a refresh replaces
+func (b *Breaker) All(ctx context.Context) ([]structures.Country, error) {
+	return b.call(ctx, "all", b.client.All, "")
+}
+
+// checks the client, or answers 503 without calling it while the breaker is open and the cooldown isn't over.
+// The check doesn't change the state of the breaker
+func (b *Breaker) Status(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	open := b.state == structures.BREAKER_OPEN && b.now().Sub(b.openedAt) < b.cooldown
+	b.mu.Unlock()
+	if open {
+		return http.StatusServiceUnavailable, nil
+	}
+	return b.client.Status(ctx)
+}
+
+// makes the lookup if the breaker lets it through, and falls back to the last good answer for the key or the
+// local country for the input if it fails
+func (b *Breaker) call(ctx context.Context, key string, lookup func(context.Context) ([]structures.Country, error),
+	input string) ([]structures.Country, error) {
+	if !b.allow() {
+		return b.stale(key, input, structures.ErrBreakerOpen)
+	}
+
+	countries, err := lookup(ctx)
+	//a country which isn't found is an answer from a client which works
+	if err == nil || errors.Is(err, structures.ErrCountryNotFound) {
+		b.success(key, countries)
+		return countries, err
+	}
+	//a request the caller gave up on says nothing about the client
+	if ctx.Err() != nil {
+		b.release()
+		return nil, err
+	}
+	b.failure(err)
+	return b.stale(key, input, err)
+}
+
+// tells if a request may go to the client, and lets one through to check the client once the cooldown is over
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case structures.BREAKER_CLOSED:
+		return true
+	case structures.BREAKER_OPEN:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = structures.BREAKER_HALFOPEN
+	}
+	if b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *Breaker) success(key string, countries []structures.Country) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != structures.BREAKER_CLOSED {
+		log.Printf("Countries API is answering again, closing the circuit breaker")
+	}
+	b.state, b.failures, b.trial = structures.BREAKER_CLOSED, 0, false
+	if countries != nil {
+		b.lastGood[key] = countries
+	}
+}
+
+func (b *Breaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == structures.BREAKER_HALFOPEN || b.failures >= b.failureLimit {
+		if b.state != structures.BREAKER_OPEN {
+			log.Printf("Countries API failed %d times in a row, opening the circuit breaker: %v", b.failures, err)
+		}
+		b.state, b.openedAt = structures.BREAKER_OPEN, b.now()
+	}
+	b.trial = false
+}
+
+// lets the next request check the client if this one was the check
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// the last good answer for the key, or else the local country for the input, or err if there is neither
+func (b *Breaker) stale(key string, input string, err error) ([]structures.Country, error) {
+	b.mu.Lock()
+	countries, ok := b.lastGood[key]
+	b.mu.Unlock()
+	if !ok {
+		if input == "" {
+			return nil, err
+		}
+		country, found := Resolve(input)
+		if !found {
+			return nil, err
+		}
+		countries = []structures.Country{country}
+	}
+
+	b.mu.Lock()
+	b.staleServed++
+	b.mu.Unlock()
+	return countries, nil
+}
+
+// state of the breaker for the status endpoint
+func (b *Breaker) Stats() structures.BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := structures.BreakerStats{
+		State:       b.state,
+		Failures:    b.failures,
+		LastError:   b.lastError,
+		StaleServed: b.staleServed,
+	}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
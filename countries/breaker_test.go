@@ -0,0 +1,88 @@
+package countries
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries/countriestest"
+	"groupXX/structures"
+)
+
+func TestBreaker(t *testing.T) {
+	server := countriestest.NewServer(testCountries)
+	defer server.Close()
+	client := NewHTTPClient(server.BaseURL)
+	client.Retries = 0
+	breaker := NewBreaker(client)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	defaultResolver := Default()
+	defer func() { SetDefault(defaultResolver) }()
+	SetDefault(New(testCountries))
+
+	//a good answer is remembered, and a country which isn't found isn't a failure
+	_, err := breaker.ByName(ctx, "Slovenia")
+	assert.NoError(t, err)
+	_, err = breaker.ByName(ctx, "Atlantis")
+	assert.Equal(t, structures.ErrCountryNotFound, err)
+	assert.Equal(t, structures.BREAKER_CLOSED, breaker.Stats().State)
+
+	//failures are answered with the last good answer, and open the breaker when they reach the limit
+	server.Fail(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway,
+		http.StatusBadGateway)
+	for i := 0; i < structures.BREAKERFAILURES; i++ {
+		found, err := breaker.ByName(ctx, "slovenia")
+		assert.NoError(t, err)
+		assert.Equal(t, "SVN", found[0].Alpha3)
+	}
+	stats := breaker.Stats()
+	assert.Equal(t, structures.BREAKER_OPEN, stats.State)
+	assert.Equal(t, structures.BREAKERFAILURES, stats.Failures)
+	assert.Equal(t, now, *stats.OpenedAt)
+	assert.Contains(t, stats.LastError, "502")
+	assert.Equal(t, int64(structures.BREAKERFAILURES), stats.StaleServed)
+
+	//while open the client isn't called, a lookup without an earlier answer is served from the local countries
+	requests := len(server.Requests())
+	found, err := breaker.ByCode(ctx, "HRV")
+	assert.NoError(t, err)
+	assert.Equal(t, "Croatia", found[0].Name.Common)
+	_, err = breaker.ByCode(ctx, "XXX")
+	assert.Equal(t, structures.ErrBreakerOpen, err)
+	_, err = breaker.All(ctx)
+	assert.Equal(t, structures.ErrBreakerOpen, err)
+	status, err := breaker.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Len(t, server.Requests(), requests)
+
+	//after the cooldown one failing check opens it again
+	now = now.Add(structures.BREAKERCOOLDOWN)
+	server.Fail(http.StatusServiceUnavailable)
+	_, err = breaker.All(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, structures.BREAKER_OPEN, breaker.Stats().State)
+	assert.Len(t, server.Requests(), requests+1)
+
+	//and one good check closes it
+	now = now.Add(structures.BREAKERCOOLDOWN)
+	found, err = breaker.All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+	stats = breaker.Stats()
+	assert.Equal(t, structures.BREAKER_CLOSED, stats.State)
+	assert.Equal(t, 0, stats.Failures)
+
+	//every country is served stale once there is a good answer
+	server.Fail(http.StatusBadGateway)
+	found, err = breaker.All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, 1, breaker.Stats().Failures)
+}
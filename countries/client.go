@@ -0,0 +1,199 @@
+package countries
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"groupXX/structures"
+)
+
+// Client looks up countries in the REST Countries format. Lookups which find nothing return
+// structures.ErrCountryNotFound
+type Client interface {
+	//countries with the common or official name
+	ByName(ctx context.Context, name string) ([]structures.Country, error)
+	//the country with the alpha-2, alpha-3 or numeric code
+	ByCode(ctx context.Context, code string) ([]structures.Country, error)
+	//every country
+	All(ctx context.Context) ([]structures.Country, error)
+	//status code of the API, for the status endpoint to check that it is up
+	Status(ctx context.Context) (int, error)
+}
+
+// the client used by the service, the countries API behind a circuit breaker unless it is replaced
+var DefaultClient Client = NewBreaker(NewHTTPClient(structures.COUNTRIESAPI))
+
+// HTTPClient gets the countries from a REST Countries API
+type HTTPClient struct {
+	//address of the API without a trailing slash, like http://129.241.150.113:8080/v3.1
+	BaseURL   string
+	UserAgent string
+	//how often a request which failed or got a 5xx is tried again, and how long to wait before the first retry
+	Retries int
+	Backoff time.Duration
+	//client the requests are made with, which has the timeout
+	HTTP *http.Client
+}
+
+// builds a client for the API at the address, with the default timeout, user agent and retries
+func NewHTTPClient(baseURL string) *HTTPClient {
+	return &HTTPClient{
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		UserAgent: structures.COUNTRIESUSERAGENT,
+		Retries:   structures.COUNTRIESRETRIES,
+		Backoff:   structures.COUNTRIESBACKOFF,
+		HTTP:      &http.Client{Timeout: structures.COUNTRIESTIMEOUT},
+	}
+}
+
+func (c *HTTPClient) ByName(ctx context.Context, name string) ([]structures.Country, error) {
+	return c.get(ctx, "/name/"+url.PathEscape(name))
+}
+
+func (c *HTTPClient) ByCode(ctx context.Context, code string) ([]structures.Country, error) {
+	return c.get(ctx, "/alpha/"+url.PathEscape(code))
+}
+
+func (c *HTTPClient) All(ctx context.Context) ([]structures.Country, error) {
+	return c.get(ctx, "/all?fields=borders,cca2,cca3,ccn3,name,altSpellings")
+}
+
+// asks the API for the code of every country, the smallest list it has, without retries. Any status code is an answer
+func (c *HTTPClient) Status(ctx context.Context) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/all?fields=cca3", nil)
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("User-Agent", c.UserAgent)
+	response, err := c.HTTP.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	response.Body.Close()
+	return response.StatusCode, nil
+}
+
+// makes the request, and tries it again after a growing wait if it fails in a way which may pass
+func (c *HTTPClient) get(ctx context.Context, path string) ([]structures.Country, error) {
+	var err error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.Backoff << uint(attempt-1)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		var countries []structures.Country
+		var retry bool
+		countries, retry, err = c.do(ctx, path)
+		if err == nil || !retry {
+			return countries, err
+		}
+	}
+	return nil, err
+}
+
+// makes one request, and tells if it is worth trying again when it fails
+func (c *HTTPClient) do(ctx context.Context, path string) ([]structures.Country, bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	request.Header.Set("User-Agent", c.UserAgent)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := c.HTTP.Do(request)
+	if err != nil {
+		//a request cancelled by the caller won't pass by trying again
+		return nil, ctx.Err() == nil, err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return nil, false, structures.ErrCountryNotFound
+	case response.StatusCode >= http.StatusInternalServerError:
+		return nil, true, fmt.Errorf("Country API returned a non-200 status code: %v", response.StatusCode)
+	case response.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("Country API returned a non-200 status code: %v", response.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	var countries []structures.Country
+	err = json.Unmarshal(body, &countries)
+	if err != nil {
+		return nil, false, err
+	}
+	return countries, false, nil
+}
+
+// FileClient looks the countries up in a file in the REST Countries format, like the countries file
+type FileClient struct {
+	Path string
+}
+
+func (c FileClient) ByName(ctx context.Context, name string) ([]structures.Country, error) {
+	return c.find(func(country structures.Country) bool {
+		return strings.EqualFold(country.Name.Common, name) || strings.EqualFold(country.Name.Official, name)
+	})
+}
+
+func (c FileClient) ByCode(ctx context.Context, code string) ([]structures.Country, error) {
+	return c.find(func(country structures.Country) bool {
+		return strings.EqualFold(country.CountryCode, code) || strings.EqualFold(country.Alpha3, code) ||
+			(country.Numeric != "" && country.Numeric == code)
+	})
+}
+
+func (c FileClient) All(ctx context.Context) ([]structures.Country, error) {
+	return c.read()
+}
+
+// the file is up if it can be read
+func (c FileClient) Status(ctx context.Context) (int, error) {
+	if _, err := c.read(); err != nil {
+		return 0, err
+	}
+	return http.StatusOK, nil
+}
+
+// the countries in the file which match
+func (c FileClient) find(match func(structures.Country) bool) ([]structures.Country, error) {
+	countries, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	var matching []structures.Country
+	for _, country := range countries {
+		if match(country) {
+			matching = append(matching, country)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, structures.ErrCountryNotFound
+	}
+	return matching, nil
+}
+
+func (c FileClient) read() ([]structures.Country, error) {
+	content, err := ioutil.ReadFile(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	var countries []structures.Country
+	err = json.Unmarshal(content, &countries)
+	if err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
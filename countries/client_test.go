@@ -0,0 +1,105 @@
+package countries
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries/countriestest"
+	"groupXX/structures"
+)
+
+var testCountries = []structures.Country{
+	{Borders: []string{"AUT", "HRV", "ITA", "HUN"}, CountryCode: "SI", Alpha3: "SVN", Numeric: "705",
+		Name: structures.CountryName{Common: "Slovenia", Official: "Republic of Slovenia"}},
+	{Borders: []string{"SVN", "HUN"}, CountryCode: "HR", Alpha3: "HRV", Numeric: "191",
+		Name: structures.CountryName{Common: "Croatia", Official: "Republic of Croatia"}},
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := countriestest.NewServer(testCountries)
+	defer server.Close()
+	client := NewHTTPClient(server.BaseURL + "/")
+	client.Backoff = time.Millisecond
+	ctx := context.Background()
+
+	found, err := client.ByName(ctx, "slovenia")
+	assert.NoError(t, err)
+	assert.Equal(t, testCountries[:1], found)
+
+	found, err = client.ByCode(ctx, "HRV")
+	assert.NoError(t, err)
+	assert.Equal(t, "Croatia", found[0].Name.Common)
+
+	found, err = client.All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	_, err = client.ByName(ctx, "Atlantis")
+	assert.Equal(t, structures.ErrCountryNotFound, err)
+
+	requests := server.Requests()
+	assert.Equal(t, structures.COUNTRIESUSERAGENT, requests[0].Header.Get("User-Agent"))
+	assert.Equal(t, "/v3.1/name/slovenia", requests[0].URL.Path)
+
+	//5xx are tried again, until the retries are used up
+	server.Fail(http.StatusServiceUnavailable, http.StatusBadGateway)
+	found, err = client.ByCode(ctx, "SI")
+	assert.NoError(t, err)
+	assert.Equal(t, "Slovenia", found[0].Name.Common)
+	assert.Len(t, server.Requests(), len(requests)+3)
+
+	server.Fail(http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	_, err = client.ByCode(ctx, "SI")
+	assert.Error(t, err)
+
+	//other errors aren't
+	server.Fail(http.StatusBadRequest)
+	_, err = client.ByCode(ctx, "SI")
+	assert.Error(t, err)
+	assert.Len(t, server.Requests(), len(requests)+7)
+
+	//the status is the status code of the API, without retries
+	status, err := client.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	server.Fail(http.StatusServiceUnavailable)
+	status, err = NewBreaker(client).Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Len(t, server.Requests(), len(requests)+9)
+
+	//an address which can't be reached times out
+	slow := NewHTTPClient("http://192.0.2.1:9")
+	slow.Retries, slow.HTTP.Timeout = 0, 10*time.Millisecond
+	_, err = slow.All(ctx)
+	assert.Error(t, err)
+}
+
+func TestFileClient(t *testing.T) {
+	client := FileClient{Path: "../functions/countriesData.json"}
+	ctx := context.Background()
+
+	found, err := client.ByName(ctx, "Slovenia")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"AUT", "HRV", "ITA", "HUN"}, found[0].Borders)
+
+	found, err = client.ByCode(ctx, "si")
+	assert.NoError(t, err)
+	assert.Equal(t, "Slovenia", found[0].Name.Common)
+
+	_, err = client.ByName(ctx, "Atlantis")
+	assert.Equal(t, structures.ErrCountryNotFound, err)
+
+	_, err = FileClient{Path: "missing.json"}.All(ctx)
+	assert.Error(t, err)
+
+	status, err := client.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	_, err = FileClient{Path: "missing.json"}.Status(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Package countriestest runs a fake REST Countries API for tests
+package countriestest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"groupXX/structures"
+)
+
+// Server answers /v3.1/all, /v3.1/name/{name} and /v3.1/alpha/{code} from a fixed list of countries
+type Server struct {
+	*httptest.Server
+	//base address of the API, to give to countries.NewHTTPClient
+	BaseURL string
+
+	mu        sync.Mutex
+	countries []structures.Country
+	requests  []*http.Request
+	//status codes the next requests are answered with instead of the countries
+	failures []int
+}
+
+// starts a server with the countries, which has to be closed when the test is done
+func NewServer(countries []structures.Country) *Server {
+	s := &Server{countries: countries}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.BaseURL = s.URL + "/v3.1"
+	return s
+}
+
+// answers the next requests with the status codes, one request each, before the countries are served again
+func (s *Server) Fail(statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statusCodes...)
+}
+
+// every request the server has got
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		s.mu.Unlock()
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v3.1")
+	var matching []structures.Country
+	switch {
+	case path == "/all":
+		matching = s.countries
+	case strings.HasPrefix(path, "/name/"):
+		//like the real API a name matches any part of the common or official name
+		name := strings.ToLower(strings.TrimPrefix(path, "/name/"))
+		for _, country := range s.countries {
+			if strings.Contains(strings.ToLower(country.Name.Common), name) ||
+				strings.Contains(strings.ToLower(country.Name.Official), name) {
+				matching = append(matching, country)
+			}
+		}
+	case strings.HasPrefix(path, "/alpha/"):
+		code := strings.TrimPrefix(path, "/alpha/")
+		for _, country := range s.countries {
+			if strings.EqualFold(country.CountryCode, code) || strings.EqualFold(country.Alpha3, code) ||
+				country.Numeric == code {
+				matching = append(matching, country)
+			}
+		}
+	default:
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	if len(matching) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status":404,"message":"Not Found"}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matching)
+}
@@ -0,0 +1,58 @@
+package countries
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// only one refresh replaces the countries at a time
+var refreshMu sync.Mutex
+
+// replaces the countries of the default resolver with every country from the client. The local countries
+// are kept if the client fails or returns something unexpected
+func Refresh(ctx context.Context, client Client) error {
+	countries, err := client.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	//a partial answer would lose countries, so every country has to have a code
+	if len(countries) == 0 {
+		return fmt.Errorf("countries API returned no countries")
+	}
+	for _, country := range countries {
+		if country.Alpha3 == "" {
+			return fmt.Errorf("countries API returned %s without an alpha-3 code", country.Name.Common)
+		}
+	}
+
+	//the check and the swap are one step, so two refreshes at once can't both build a new resolver
+	refreshMu.Lock()
+	if resolver := Default(); resolver == nil {
+		SetDefault(New(countries))
+	} else {
+		resolver.Replace(countries)
+	}
+	refreshMu.Unlock()
+	log.Printf("Refreshed %d countries", len(countries))
+	return nil
+}
+
+// refreshes the countries at once and then every interval until stop is closed, a nil stop never stops
+func WatchCountries(client Client, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := Refresh(context.Background(), client); err != nil {
+			log.Printf("Error refreshing countries, keeping the local countries: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
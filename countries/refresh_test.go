@@ -0,0 +1,94 @@
+package countries
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries/countriestest"
+	"groupXX/structures"
+)
+
+func TestNeighbours(t *testing.T) {
+	resolver, err := Load("../structures/countries.json")
+	if err != nil {
+		t.Fatalf("Load() returned an error: %v", err)
+	}
+
+	neighbours, ok := resolver.Neighbours("Kingdom of Norway")
+	assert.True(t, ok)
+	var codes []string
+	for _, neighbour := range neighbours {
+		codes = append(codes, neighbour.Alpha3)
+	}
+	assert.ElementsMatch(t, []string{"FIN", "SWE", "RUS"}, codes)
+
+	neighbours, ok = resolver.Neighbours("Iceland")
+	assert.True(t, ok)
+	assert.Empty(t, neighbours)
+
+	_, ok = resolver.Neighbours("Atlantis")
+	assert.False(t, ok)
+}
+
+func TestRefresh(t *testing.T) {
+	server := countriestest.NewServer([]structures.Country{
+		{Borders: []string{"SWE"}, CountryCode: "NO", Alpha3: "NOR", Name: structures.CountryName{Common: "Norway"}},
+		{Borders: []string{"NOR"}, CountryCode: "SE", Alpha3: "SWE", Name: structures.CountryName{Common: "Sweden"}},
+	})
+	defer server.Close()
+	client := NewHTTPClient(server.BaseURL)
+	client.Retries = 0
+
+	defaultResolver := Default()
+	defer func() { SetDefault(defaultResolver) }()
+	SetDefault(New([]structures.Country{{Alpha3: "DNK", Name: structures.CountryName{Common: "Denmark"}}}))
+
+	//a failed refresh keeps the countries
+	server.Fail(http.StatusBadGateway)
+	assert.Error(t, Refresh(context.Background(), client))
+	partial := countriestest.NewServer([]structures.Country{{Name: structures.CountryName{Common: "Norway"}}})
+	defer partial.Close()
+	assert.Error(t, Refresh(context.Background(), NewHTTPClient(partial.BaseURL)))
+	_, ok := Resolve("Denmark")
+	assert.True(t, ok)
+
+	assert.NoError(t, Refresh(context.Background(), client))
+	_, ok = Resolve("Denmark")
+	assert.False(t, ok)
+	neighbours, ok := Default().Neighbours("no")
+	assert.True(t, ok)
+	assert.Equal(t, "Sweden", neighbours[0].Name.Common)
+}
+
+func TestRefreshWhileResolving(t *testing.T) {
+	server := countriestest.NewServer([]structures.Country{
+		{CountryCode: "NO", Alpha3: "NOR", Name: structures.CountryName{Common: "Norway"}},
+	})
+	defer server.Close()
+	client := NewHTTPClient(server.BaseURL)
+
+	defaultResolver := Default()
+	defer func() { SetDefault(defaultResolver) }()
+	SetDefault(nil)
+
+	//run with -race, refreshes replace the resolver while it is read
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Refresh(context.Background(), client))
+		}()
+		go func() {
+			defer wg.Done()
+			Resolve("Norway")
+		}()
+	}
+	wg.Wait()
+	_, ok := Resolve("Norway")
+	assert.True(t, ok)
+}
@@ -0,0 +1,247 @@
+package countries
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	"groupXX/structures"
+)
+
+// Resolver maps every way of writing a country to one canonical country, identified by its ISO alpha-3 code
+type Resolver struct {
+	//held while the countries are replaced by a refresh
+	mu        sync.RWMutex
+	countries []structures.Country
+	//normalised name or code -> index in countries
+	byKey map[string]int
+	//upper case alpha-3 code -> index in countries, which is how borders are given
+	byAlpha3 map[string]int
+}
+
+// the resolver used by the service, swapped as a whole since a refresh may set it while it is in use
+var current atomic.Value
+
+func init() {
+	resolver, err := Load(structures.COUNTRIESFILE)
+	if err != nil {
+		log.Printf("Error loading countries file: %v", err)
+	}
+	SetDefault(resolver)
+}
+
+// returns the resolver used by the service, nil if the countries file couldn't be loaded
+func Default() *Resolver {
+	return current.Load().(*Resolver)
+}
+
+// replaces the resolver used by the service
+func SetDefault(resolver *Resolver) {
+	current.Store(resolver)
+}
+
+// curated names which are neither the common, official or alternative name, keyed by alpha-3 code
+var aliases = map[string][]string{
+	"GBR": {"UK", "U.K.", "Great Britain", "Britain", "England"},
+	"USA": {"US", "U.S.", "U.S.A.", "America", "United States of America"},
+	"CIV": {"Cote d'Ivoire", "Ivory Coast"},
+	"CZE": {"Czech Republic"},
+	"KOR": {"Korea", "Republic of Korea"},
+	"PRK": {"DPRK"},
+	"RUS": {"Russian Federation"},
+	"COD": {"Democratic Republic of Congo", "Democratic Republic of the Congo", "DRC", "Congo-Kinshasa"},
+	"COG": {"Congo", "Congo-Brazzaville"},
+	"MKD": {"Macedonia"},
+	"SWZ": {"Swaziland"},
+	"TLS": {"Timor", "East Timor"},
+	"MMR": {"Burma"},
+	"CPV": {"Cabo Verde"},
+	"TUR": {"Turkiye"},
+	"NLD": {"Holland", "The Netherlands"},
+	"VAT": {"Holy See", "Vatican"},
+	"MAC": {"Macao"},
+	"ARE": {"UAE", "Emirates"},
+	"FSM": {"Micronesia (country)"},
+	"PSE": {"Palestinian Territories"},
+	"BHS": {"The Bahamas"},
+	"GMB": {"The Gambia"},
+	"IRN": {"Persia"},
+	"LAO": {"Lao"},
+	"VNM": {"Viet Nam"},
+}
+
+// loads the countries from a file in the REST Countries format
+func Load(path string) (*Resolver, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var countries []structures.Country
+	err = json.Unmarshal(content, &countries)
+	if err != nil {
+		return nil, err
+	}
+	return New(countries), nil
+}
+
+// builds a resolver from a list of countries
+func New(countries []structures.Country) *Resolver {
+	r := &Resolver{}
+	r.build(countries)
+	return r
+}
+
+// replaces the countries, for example with the ones from a refresh, while the resolver is in use
+func (r *Resolver) Replace(countries []structures.Country) {
+	replaced := &Resolver{}
+	replaced.build(countries)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.countries, r.byKey, r.byAlpha3 = replaced.countries, replaced.byKey, replaced.byAlpha3
+}
+
+// indexes the countries, the resolver must not be in use
+func (r *Resolver) build(countries []structures.Country) {
+	r.countries, r.byKey, r.byAlpha3 = countries, make(map[string]int), make(map[string]int)
+	for i, country := range countries {
+		r.byAlpha3[strings.ToUpper(country.Alpha3)] = i
+	}
+
+	//added from the most to the least specific, the first one to claim a key keeps it
+	for i, country := range countries {
+		r.add(country.Alpha3, i)
+		r.add(country.CountryCode, i)
+	}
+	for i, country := range countries {
+		r.add(country.Numeric, i)
+		//numeric codes are also accepted without their leading zeros
+		if numeric, err := strconv.Atoi(country.Numeric); err == nil {
+			r.add(strconv.Itoa(numeric), i)
+		}
+	}
+	for i, country := range countries {
+		r.add(country.Name.Common, i)
+	}
+	for i, country := range countries {
+		r.add(country.Name.Official, i)
+	}
+	for i, country := range countries {
+		for _, spelling := range country.AltSpellings {
+			r.add(spelling, i)
+		}
+	}
+	for alpha3, names := range aliases {
+		i, ok := r.byAlpha3[alpha3]
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			r.add(name, i)
+		}
+	}
+}
+
+// adds a key unless it is empty or already taken
+func (r *Resolver) add(key string, i int) {
+	key = Normalise(key)
+	if key == "" {
+		return
+	}
+	if _, taken := r.byKey[key]; !taken {
+		r.byKey[key] = i
+	}
+}
+
+// returns the country the input refers to, and if it was found
+func (r *Resolver) Resolve(input string) (structures.Country, bool) {
+	if r == nil {
+		return structures.Country{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.byKey[Normalise(input)]
+	if !ok {
+		return structures.Country{}, false
+	}
+	return r.countries[i], true
+}
+
+// returns the countries bordering the country the input refers to, and if that country was found.
+// Border codes which aren't in the countries are left out
+func (r *Resolver) Neighbours(input string) ([]structures.Country, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.byKey[Normalise(input)]
+	if !ok {
+		return nil, false
+	}
+	neighbours := make([]structures.Country, 0, len(r.countries[i].Borders))
+	for _, border := range r.countries[i].Borders {
+		if j, ok := r.byAlpha3[strings.ToUpper(border)]; ok {
+			neighbours = append(neighbours, r.countries[j])
+		}
+	}
+	return neighbours, true
+}
+
+// returns every country known by the resolver
+func (r *Resolver) All() []structures.Country {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.countries
+}
+
+// resolves with the default resolver
+func Resolve(input string) (structures.Country, bool) {
+	return Default().Resolve(input)
+}
+
+// returns the alpha-3 code of the country the input refers to, empty if it isn't known
+func ISO3(input string) string {
+	country, ok := Resolve(input)
+	if !ok {
+		return ""
+	}
+	return country.Alpha3
+}
+
+// removes accents, case, punctuation and a leading "the", so "Côte d'Ivoire" and "cote divoire" are equal
+func Normalise(input string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), input)
+	if err != nil {
+		folded = input
+	}
+
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(folded) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case r == '\'' || r == '’' || r == '.':
+			//dropped without a space, so "U.K." is "uk"
+		default:
+			space = true
+		}
+	}
+	return strings.TrimPrefix(b.String(), "the ")
+}
@@ -0,0 +1,70 @@
+package countries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	resolver, err := Load("../structures/countries.json")
+	if err != nil {
+		t.Fatalf("Load() returned an error: %v", err)
+	}
+
+	testCases := []struct {
+		input  string
+		alpha3 string
+	}{
+		{"NOR", "NOR"},
+		{"nor", "NOR"},
+		{"NO", "NOR"},
+		{"578", "NOR"},
+		{"Norway", "NOR"},
+		{"Kingdom of Norway", "NOR"},
+		{"  norway ", "NOR"},
+		{"UK", "GBR"},
+		{"United Kingdom", "GBR"},
+		{"Côte d'Ivoire", "CIV"},
+		{"cote divoire", "CIV"},
+		{"Ivory Coast", "CIV"},
+		{"United States of America", "USA"},
+		{"U.S.A.", "USA"},
+		{"The Netherlands", "NLD"},
+		{"Czechia", "CZE"},
+		{"Czech Republic", "CZE"},
+		{"Russian Federation", "RUS"},
+		{"South Korea", "KOR"},
+		{"Democratic Republic of Congo", "COD"},
+		{"Türkiye", "TUR"},
+		{"36", "AUS"},
+		{"036", "AUS"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			country, ok := resolver.Resolve(tc.input)
+			assert.True(t, ok)
+			assert.Equal(t, tc.alpha3, country.Alpha3)
+		})
+	}
+
+	for _, input := range []string{"Atlantis", "Europe", "", "World"} {
+		_, ok := resolver.Resolve(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestNormalise(t *testing.T) {
+	assert.Equal(t, "cote divoire", Normalise("Côte d’Ivoire"))
+	assert.Equal(t, "sao tome and principe", Normalise("São Tomé and Príncipe"))
+	assert.Equal(t, "uk", Normalise("U.K."))
+	assert.Equal(t, "gambia", Normalise("The Gambia"))
+	assert.Equal(t, "guinea bissau", Normalise("Guinea-Bissau"))
+}
+
+func TestNilResolver(t *testing.T) {
+	var resolver *Resolver
+	_, ok := resolver.Resolve("Norway")
+	assert.False(t, ok)
+}
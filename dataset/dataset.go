@@ -0,0 +1,251 @@
+package dataset
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"groupXX/countries"
+	"groupXX/structures"
+)
+
+// Dataset is an index of the energy data by iso code, name and year. It is never changed after it
+// is built, so it can be shared between requests without locking
+type Dataset struct {
+	entries []structures.DataEntry
+	//one slice per country, sorted by year
+	series [][]structures.DataEntry
+	//upper case iso code -> index in series
+	byCode map[string]int
+	//normalised name -> index in series
+	byName map[string]int
+	byYear map[int][]structures.DataEntry
+	//the earliest and the latest year of any country
+	earliestYear int
+	latestYear   int
+	//hash of the entries, changes when the data does
+	version string
+
+	//metric of the percentages, and the table the dataset was projected from if it was read from a file
+	metric      string
+	table       *Table
+	projections *projections
+}
+
+// builds the index of entries of the default metric, the entries can be in any order
+func New(entries []structures.DataEntry) *Dataset {
+	d := &Dataset{
+		entries: entries,
+		byCode:  make(map[string]int),
+		byName:  make(map[string]int),
+		byYear:  make(map[int][]structures.DataEntry),
+	}
+
+	hash := fnv.New64a()
+	for _, entry := range entries {
+		fmt.Fprintf(hash, "%s|%s|%d|%v\n", entry.Country, entry.CountryCode, entry.Year, entry.Percentage)
+
+		name := countries.Normalise(entry.Country)
+		i, ok := d.byName[name]
+		if !ok {
+			i = len(d.series)
+			d.series = append(d.series, nil)
+			d.byName[name] = i
+			//aggregates like Europe have no code
+			if entry.CountryCode != "" {
+				d.byCode[strings.ToUpper(entry.CountryCode)] = i
+			}
+		}
+		d.series[i] = append(d.series[i], entry)
+		d.byYear[entry.Year] = append(d.byYear[entry.Year], entry)
+		if entry.Year > d.latestYear {
+			d.latestYear = entry.Year
+		}
+		if d.earliestYear == 0 || entry.Year < d.earliestYear {
+			d.earliestYear = entry.Year
+		}
+	}
+
+	for _, series := range d.series {
+		sort.SliceStable(series, func(i, j int) bool { return series[i].Year < series[j].Year })
+	}
+	d.version = fmt.Sprintf("%016x", hash.Sum64())
+	return d
+}
+
+// checks that the entries can be used before they replace the data in use
+func Validate(entries []structures.DataEntry) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("Error: the data has no entries")
+	}
+	maxYear := time.Now().Year() + 1
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		if entry.Country == "" {
+			return fmt.Errorf("Error: entry %d has no country", i+1)
+		}
+		if entry.Year < 1800 || entry.Year > maxYear {
+			return fmt.Errorf("Error: %s has an invalid year %d", entry.Country, entry.Year)
+		}
+		if entry.Percentage < 0 || entry.Percentage > 100 {
+			return fmt.Errorf("Error: %s has an invalid percentage %v for %d", entry.Country, entry.Percentage, entry.Year)
+		}
+		key := fmt.Sprintf("%s|%d", entry.Country, entry.Year)
+		if seen[key] {
+			return fmt.Errorf("Error: %s has more than one entry for %d", entry.Country, entry.Year)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// finds the series of a code or name, falling back to the country resolver for other codes and aliases
+func (d *Dataset) lookup(input string) ([]structures.DataEntry, bool) {
+	if d == nil {
+		return nil, false
+	}
+	if i, ok := d.byCode[strings.ToUpper(strings.TrimSpace(input))]; ok {
+		return d.series[i], true
+	}
+	if i, ok := d.byName[countries.Normalise(input)]; ok {
+		return d.series[i], true
+	}
+	if code := countries.ISO3(input); code != "" {
+		if i, ok := d.byCode[code]; ok {
+			return d.series[i], true
+		}
+	}
+	return nil, false
+}
+
+// reports if there is any data for the country
+func (d *Dataset) Has(input string) bool {
+	_, ok := d.lookup(input)
+	return ok
+}
+
+// returns every year of a country sorted by year, nil if it isn't in the data
+func (d *Dataset) Country(input string) []structures.DataEntry {
+	series, ok := d.lookup(input)
+	if !ok {
+		return nil
+	}
+	return clone(series)
+}
+
+// returns the years of a country from begin to end, both included and both optional
+func (d *Dataset) Range(input string, begin *int, end *int) []structures.DataEntry {
+	series, ok := d.lookup(input)
+	if !ok {
+		return nil
+	}
+	from, to := 0, len(series)
+	if begin != nil {
+		from = sort.Search(len(series), func(i int) bool { return series[i].Year >= *begin })
+	}
+	if end != nil {
+		to = sort.Search(len(series), func(i int) bool { return series[i].Year > *end })
+	}
+	if from >= to {
+		return nil
+	}
+	return clone(series[from:to])
+}
+
+// returns the latest year of a country as a slice of one, nil if it isn't in the data
+func (d *Dataset) Current(input string) []structures.DataEntry {
+	series, ok := d.lookup(input)
+	if !ok {
+		return nil
+	}
+	return clone(series[len(series)-1:])
+}
+
+// returns every entry from begin to end, both included and both optional, in the order they were loaded
+func (d *Dataset) Between(begin *int, end *int) []structures.DataEntry {
+	if d == nil {
+		return nil
+	}
+	if begin == nil && end == nil {
+		return d.All()
+	}
+	var entries []structures.DataEntry
+	for _, entry := range d.entries {
+		if (begin == nil || entry.Year >= *begin) && (end == nil || entry.Year <= *end) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// returns every country with data for the year
+func (d *Dataset) Year(year int) []structures.DataEntry {
+	if d == nil {
+		return nil
+	}
+	return clone(d.byYear[year])
+}
+
+// returns every entry in the order they were loaded
+func (d *Dataset) All() []structures.DataEntry {
+	if d == nil {
+		return nil
+	}
+	return clone(d.entries)
+}
+
+// returns the latest year of every country, in the order they were loaded
+func (d *Dataset) Countries() []structures.DataEntry {
+	if d == nil {
+		return nil
+	}
+	latest := make([]structures.DataEntry, len(d.series))
+	for i, series := range d.series {
+		latest[i] = series[len(series)-1]
+	}
+	return latest
+}
+
+// the latest year any country has data for
+func (d *Dataset) LatestYear() int {
+	if d == nil {
+		return 0
+	}
+	return d.latestYear
+}
+
+// the earliest year any country has data for
+func (d *Dataset) EarliestYear() int {
+	if d == nil {
+		return 0
+	}
+	return d.earliestYear
+}
+
+// number of entries
+func (d *Dataset) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.entries)
+}
+
+// identifies the content of the data, two datasets with the same entries have the same version
+func (d *Dataset) Version() string {
+	if d == nil {
+		return ""
+	}
+	return d.version
+}
+
+// copies so callers which sort the result don't change the index
+func clone(entries []structures.DataEntry) []structures.DataEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	copied := make([]structures.DataEntry, len(entries))
+	copy(copied, entries)
+	return copied
+}
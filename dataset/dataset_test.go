@@ -0,0 +1,151 @@
+package dataset
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/structures"
+)
+
+var testData = []structures.DataEntry{
+	{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.5},
+	{Country: "Norway", CountryCode: "NOR", Year: 2019, Percentage: 67.1},
+	{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 70.9},
+	{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9},
+	{Country: "Europe", CountryCode: "", Year: 2021, Percentage: 23.1},
+	{Country: "Europe", CountryCode: "", Year: 2020, Percentage: 22.4},
+}
+
+func TestCountry(t *testing.T) {
+	d := New(testData)
+
+	//by code and by name, regardless of case, sorted by year
+	for _, input := range []string{"NOR", "nor", "Norway", " NORWAY "} {
+		data := d.Country(input)
+		assert.Len(t, data, 3, input)
+		assert.Equal(t, []int{2019, 2020, 2021}, years(data), input)
+	}
+	assert.Equal(t, []int{2020, 2021}, years(d.Country("europe")))
+	assert.Nil(t, d.Country("Atlantis"))
+	assert.Nil(t, d.Country(""))
+	assert.True(t, d.Has("swe"))
+	assert.False(t, d.Has("Atlantis"))
+
+	//changing the result doesn't change the index
+	data := d.Country("NOR")
+	data[0].Year = 1900
+	assert.Equal(t, 2019, d.Country("NOR")[0].Year)
+}
+
+func TestRange(t *testing.T) {
+	d := New(testData)
+	year := func(y int) *int { return &y }
+
+	assert.Equal(t, []int{2019, 2020, 2021}, years(d.Range("NOR", nil, nil)))
+	assert.Equal(t, []int{2020, 2021}, years(d.Range("NOR", year(2020), nil)))
+	assert.Equal(t, []int{2019, 2020}, years(d.Range("NOR", nil, year(2020))))
+	assert.Equal(t, []int{2020}, years(d.Range("NOR", year(2020), year(2020))))
+	assert.Equal(t, []int{2019, 2020, 2021}, years(d.Range("NOR", year(1900), year(2100))))
+	assert.Nil(t, d.Range("NOR", year(2022), nil))
+	assert.Nil(t, d.Range("NOR", year(2021), year(2019)))
+	assert.Nil(t, d.Range("Atlantis", nil, nil))
+}
+
+func TestYearAndAll(t *testing.T) {
+	d := New(testData)
+
+	current := d.Year(2021)
+	assert.Len(t, current, 3)
+	assert.Nil(t, d.Year(1900))
+	//in the order they were loaded
+	assert.Equal(t, testData, d.All())
+}
+
+func TestNilDataset(t *testing.T) {
+	var d *Dataset
+	assert.Nil(t, d.Country("NOR"))
+	assert.Nil(t, d.Range("NOR", nil, nil))
+	assert.Nil(t, d.Year(2021))
+	assert.Nil(t, d.All())
+	assert.False(t, d.Has("NOR"))
+}
+
+func years(data []structures.DataEntry) []int {
+	years := make([]int, 0, len(data))
+	for _, entry := range data {
+		years = append(years, entry.Year)
+	}
+	return years
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(testData))
+	assert.Error(t, Validate(nil))
+	assert.Error(t, Validate([]structures.DataEntry{{Country: "", Year: 2021}}))
+	assert.Error(t, Validate([]structures.DataEntry{{Country: "Norway", Year: 3000}}))
+	assert.Error(t, Validate([]structures.DataEntry{{Country: "Norway", Year: 2021, Percentage: -1}}))
+	assert.Error(t, Validate([]structures.DataEntry{{Country: "Norway", Year: 2021}, {Country: "Norway", Year: 2021}}))
+}
+
+func TestVersion(t *testing.T) {
+	assert.Equal(t, New(testData).Version(), New(testData).Version())
+	assert.NotEqual(t, New(testData).Version(), New(testData[1:]).Version())
+}
+
+func TestCurrent(t *testing.T) {
+	d := New(append(testData, structures.DataEntry{Country: "Denmark", CountryCode: "DNK", Year: 2019, Percentage: 35.2}))
+
+	assert.Equal(t, 2021, d.LatestYear())
+	assert.Equal(t, 2019, d.EarliestYear())
+	assert.Equal(t, []int{2021}, years(d.Current("nor")))
+	//the latest year of the country, even if other countries have later years
+	assert.Equal(t, []int{2019}, years(d.Current("Denmark")))
+	assert.Nil(t, d.Current("Atlantis"))
+	assert.Equal(t, 0, New(nil).LatestYear())
+	assert.Equal(t, 0, New(nil).EarliestYear())
+}
+
+func TestBetween(t *testing.T) {
+	d := New(testData)
+	year := func(y int) *int { return &y }
+
+	assert.Equal(t, testData, d.Between(nil, nil))
+	assert.Equal(t, []int{2021, 2021, 2021}, years(d.Between(year(2021), nil)))
+	assert.Equal(t, []int{2019, 2020, 2020}, years(d.Between(nil, year(2020))))
+	assert.Nil(t, d.Between(year(2022), nil))
+}
+
+func TestMetric(t *testing.T) {
+	nan := math.NaN()
+	table := &Table{
+		Rows: []structures.DataEntry{
+			{Country: "Norway", CountryCode: "NOR", Year: 2020},
+			{Country: "Norway", CountryCode: "NOR", Year: 2021},
+		},
+		Columns: map[string][]float64{
+			structures.DEFAULTMETRIC: {70.9, 71.5},
+			"solar_share_energy":     {nan, 0.03},
+		},
+	}
+	d := FromTable(table)
+	assert.Equal(t, structures.DEFAULTMETRIC, d.MetricName())
+	assert.Equal(t, []string{structures.DEFAULTMETRIC, "solar_share_energy"}, d.Metrics())
+
+	solar, ok := d.Metric("solar_share_energy")
+	assert.True(t, ok)
+	assert.Equal(t, "solar_share_energy", solar.MetricName())
+	assert.Equal(t, []structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 0.03}}, solar.All())
+	//built once, and every metric can reach the others
+	again, _ := d.Metric("SOLAR_SHARE_ENERGY")
+	assert.Same(t, solar, again)
+	back, _ := solar.Metric(structures.DEFAULTMETRIC)
+	assert.Same(t, d, back)
+
+	_, ok = d.Metric("wind_share_energy")
+	assert.False(t, ok)
+	//a dataset which isn't from a table only has the default metric
+	_, ok = New(testData).Metric("solar_share_energy")
+	assert.False(t, ok)
+}
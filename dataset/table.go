@@ -0,0 +1,100 @@
+package dataset
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"groupXX/structures"
+)
+
+// Table is the data as it was read, one row per country and year with the value of every metric in the file
+type Table struct {
+	//country, iso code and year of each row, the percentage is not used
+	Rows []structures.DataEntry
+	//lower case metric name -> value for each row, NaN where the row has no value
+	Columns map[string][]float64
+}
+
+// datasets of the other metrics of a table, built the first time they are asked for
+type projections struct {
+	mu       sync.Mutex
+	datasets map[string]*Dataset
+}
+
+// builds the dataset of the default metric, which is what the service shows unless another metric is asked for
+func FromTable(table *Table) *Dataset {
+	shared := &projections{datasets: make(map[string]*Dataset)}
+	d := project(table, structures.DEFAULTMETRIC)
+	d.table, d.projections = table, shared
+	shared.datasets[structures.DEFAULTMETRIC] = d
+	return d
+}
+
+// the rows of the table with a value for the metric, with the value as the percentage
+func project(table *Table, metric string) *Dataset {
+	var entries []structures.DataEntry
+	for i, value := range table.Columns[metric] {
+		if math.IsNaN(value) {
+			continue
+		}
+		entry := table.Rows[i]
+		entry.Percentage = value
+		entries = append(entries, entry)
+	}
+	d := New(entries)
+	d.metric = metric
+	return d
+}
+
+// name of the metric the percentages of the dataset are from
+func (d *Dataset) MetricName() string {
+	if d == nil || d.metric == "" {
+		return structures.DEFAULTMETRIC
+	}
+	return d.metric
+}
+
+// returns the dataset of another metric from the same file, and if the file has it
+func (d *Dataset) Metric(metric string) (*Dataset, bool) {
+	if d == nil {
+		return nil, false
+	}
+	metric = strings.ToLower(metric)
+	if metric == "" || metric == d.MetricName() {
+		return d, true
+	}
+	if d.table == nil {
+		return nil, false
+	}
+	if _, ok := d.table.Columns[metric]; !ok {
+		return nil, false
+	}
+
+	d.projections.mu.Lock()
+	defer d.projections.mu.Unlock()
+	projected, ok := d.projections.datasets[metric]
+	if !ok {
+		projected = project(d.table, metric)
+		projected.table, projected.projections = d.table, d.projections
+		d.projections.datasets[metric] = projected
+	}
+	return projected, true
+}
+
+// names of every metric which can be asked for, sorted
+func (d *Dataset) Metrics() []string {
+	if d == nil {
+		return nil
+	}
+	if d.table == nil {
+		return []string{d.MetricName()}
+	}
+	metrics := make([]string, 0, len(d.table.Columns))
+	for metric := range d.table.Columns {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics
+}
@@ -1,26 +1,28 @@
 package firebase
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"groupXX/structures"
-	"log"
-	"net/http"
-	"time"
+	"sort"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func CreateFirestoreClient(ctx context.Context) (*firestore.Client, error) {
-	//sets up the Firestore client
-	projectID := "group66assignment2"
+// Store keeps webhooks and cached searches in a Firestore project
+type Store struct {
+	client *firestore.Client
+}
+
+func CreateFirestoreClient(ctx context.Context, projectID string, credentials string) (*firestore.Client, error) {
 	//refers to the credentials JSON file
-	credentials := "./.secrets/group66assignment2-firebase-adminsdk-pv6iv-c0b5b34aeb.json"
 	opt := option.WithCredentialsFile(credentials)
 	//creates a client eith the context, projectID and credentials
 	client, err := firestore.NewClient(ctx, projectID, opt)
@@ -31,25 +33,113 @@ func CreateFirestoreClient(ctx context.Context) (*firestore.Client, error) {
 	return client, nil
 }
 
+// creates a store with one client which is shared by every call
+func NewStore(ctx context.Context, projectID string, credentials string) (*Store, error) {
+	client, err := CreateFirestoreClient(ctx, projectID, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{client: client}, nil
+}
+
+// closes the underlying client
+func (s *Store) Close() error {
+	return s.client.Close()
+}
+
+// reads at most one webhook, which fails if the project can't be reached
+func (s *Store) Ping(ctx context.Context) error {
+	_, err := s.client.Collection("webhooks").Limit(1).Documents(ctx).GetAll()
+	return err
+}
+
+func (s *Store) Backend() string {
+	return structures.STORAGE_FIRESTORE
+}
+
 // stores a new webhook to the firestore
-func StoreWebhooks(ctx context.Context, client *firestore.Client, webhook structures.Webhook) (string, error) {
-	doc, _, err := client.Collection("webhooks").Add(ctx, webhook)
+func (s *Store) StoreWebhook(ctx context.Context, webhook structures.Webhook) (string, error) {
+	doc, _, err := s.client.Collection("webhooks").Add(ctx, webhook)
 	if err != nil {
 		return "", err
 	}
 	return doc.ID, nil
 }
 
-// deletes a webhook from the firestore
-func DeleteWebhook(ctx context.Context, client *firestore.Client, id string) error {
-	_, err := client.Collection("webhooks").Doc(id).Delete(ctx)
+// replaces an existing webhook in the firestore
+func (s *Store) UpdateWebhook(ctx context.Context, id string, webhook structures.Webhook) error {
+	docRef := s.client.Collection("webhooks").Doc(id)
+	//in a transaction so a webhook deleted at the same time isn't recreated
+	return s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return structures.ErrWebhookNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Set(docRef, webhook)
+	})
+}
+
+// updates only the last state field, firestore reports a webhook which doesn't exist instead of creating it
+func (s *Store) SetWebhookState(ctx context.Context, id string, state string) error {
+	return s.updateWebhookField(ctx, id, "LastState", state)
+}
+
+// updates only the secret field, like SetWebhookState
+func (s *Store) SetWebhookSecret(ctx context.Context, id string, secret string) error {
+	return s.updateWebhookField(ctx, id, "Secret", secret)
+}
+
+func (s *Store) updateWebhookField(ctx context.Context, id string, field string, value interface{}) error {
+	_, err := s.client.Collection("webhooks").Doc(id).Update(ctx, []firestore.Update{{Path: field, Value: value}})
+	if status.Code(err) == codes.NotFound {
+		return structures.ErrWebhookNotFound
+	}
+	return err
+}
+
+// a batch of writes to firestore can have at most this many writes
+const maxBatchWrites = 500
+
+// deletes a webhook from the firestore, firestore doesn't delete the collections of a document with it, so the
+// delivery attempts are deleted in batches first
+func (s *Store) DeleteWebhook(ctx context.Context, id string) error {
+	doc := s.client.Collection("webhooks").Doc(id)
+	deliveries, err := doc.Collection("deliveries").DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(deliveries); start += maxBatchWrites {
+		end := start + maxBatchWrites
+		if end > len(deliveries) {
+			end = len(deliveries)
+		}
+		batch := s.client.Batch()
+		for _, delivery := range deliveries[start:end] {
+			batch.Delete(delivery)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return err
+		}
+	}
+
+	//the precondition makes firestore report missing documents instead of silently ignoring them
+	_, err = doc.Delete(ctx, firestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return structures.ErrWebhookNotFound
+	}
 	return err
 }
 
 // retrieves a webhook from the firestore
-func GetWebhook(ctx context.Context, client *firestore.Client, id string) (structures.Webhook, error) {
+func (s *Store) GetWebhook(ctx context.Context, id string) (structures.Webhook, error) {
 	wh := structures.Webhook{}
-	snapshot, err := client.Collection("webhooks").Doc(id).Get(ctx)
+	snapshot, err := s.client.Collection("webhooks").Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return wh, structures.ErrWebhookNotFound
+	}
 	if err != nil {
 		return wh, err
 	}
@@ -60,27 +150,12 @@ func GetWebhook(ctx context.Context, client *firestore.Client, id string) (struc
 	return wh, nil
 }
 
-func GetNumWebhooks(ctx context.Context, client *firestore.Client) (int, error) {
-	webhooks, err := client.Collection("webhooks").Documents(ctx).GetAll()
-	if err != nil {
-		return 0, err
-	}
-	return len(webhooks), nil
-}
-
-var calls = make(map[string]int)
-
-// Updates call count for country and checks if any of the webhooks are to be invocated
-func UpdateCalls(country string) error {
-	//increments call by 1
-	calls[country] += 1
-	ctx := context.Background()
-	client, err := CreateFirestoreClient(ctx)
-	if err != nil {
-		return err
-	}
-	iter := client.Collection("webhooks").Documents(ctx)
+// retrieves every registered webhook together with its id
+func (s *Store) GetAllWebhooks(ctx context.Context) ([]structures.WebhookRegistration, error) {
+	iter := s.client.Collection("webhooks").Documents(ctx)
 	defer iter.Stop()
+
+	webhooks := make([]structures.WebhookRegistration, 0)
 	//infinite for loop to loop until break
 	for {
 		doc, err := iter.Next()
@@ -89,45 +164,135 @@ func UpdateCalls(country string) error {
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
-		//struct for webhook
-		wh := structures.Webhook{}
 		//parse data into struct
+		wh := structures.Webhook{}
 		err = doc.DataTo(&wh)
 		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, structures.WebhookRegistration{ID: doc.Ref.ID, Webhook: wh})
+	}
+	return webhooks, nil
+}
+
+func (s *Store) GetNumWebhooks(ctx context.Context) (int, error) {
+	webhooks, err := s.client.Collection("webhooks").Documents(ctx).GetAll()
+	if err != nil {
+		return 0, err
+	}
+	return len(webhooks), nil
+}
+
+// records a delivery attempt in the deliveries collection of the webhook
+func (s *Store) AddDelivery(ctx context.Context, delivery structures.Delivery) error {
+	_, _, err := s.client.Collection("webhooks").Doc(delivery.WebhookID).Collection("deliveries").Add(ctx, delivery)
+	return err
+}
+
+// returns the latest MAXDELIVERYRECORDS delivery attempts of a webhook, oldest first
+func (s *Store) GetDeliveries(ctx context.Context, webhookID string) ([]structures.Delivery, error) {
+	docs, err := s.client.Collection("webhooks").Doc(webhookID).Collection("deliveries").
+		OrderBy("Time", firestore.Desc).Limit(structures.MAXDELIVERYRECORDS).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]structures.Delivery, len(docs))
+	for i, doc := range docs {
+		//the query is newest first, so they are placed from the back
+		err = doc.DataTo(&deliveries[len(docs)-1-i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+func (s *Store) AddDeadLetter(ctx context.Context, letter structures.DeadLetter) (string, error) {
+	doc := s.client.Collection("deadletters").NewDoc()
+	letter.ID = doc.ID
+	_, err := doc.Set(ctx, letter)
+	if err != nil {
+		return "", err
+	}
+	return letter.ID, nil
+}
+
+// returns the dead letters of a webhook, oldest first
+func (s *Store) GetDeadLetters(ctx context.Context, webhookID string) ([]structures.DeadLetter, error) {
+	docs, err := s.client.Collection("deadletters").Where("WebhookID", "==", webhookID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	letters := make([]structures.DeadLetter, len(docs))
+	for i, doc := range docs {
+		err = doc.DataTo(&letters[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	//sorted here instead of in the query so no composite index is needed
+	sort.Slice(letters, func(i, j int) bool { return letters[i].Time.Before(letters[j].Time) })
+	return letters, nil
+}
+
+func (s *Store) DeleteDeadLetter(ctx context.Context, id string) error {
+	_, err := s.client.Collection("deadletters").Doc(id).Delete(ctx, firestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return structures.ErrDeadLetterNotFound
+	}
+	return err
+}
+
+// increments the call counter of the country and returns the new count, in a transaction
+// so every instance of the service sees each count exactly once
+func (s *Store) IncrementCalls(ctx context.Context, country string) (int64, error) {
+	docRef := s.client.Collection("calls").Doc(country)
+	var count int64
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		count = 0
+		snapshot, err := tx.Get(docRef)
+		if err != nil && status.Code(err) != codes.NotFound {
 			return err
 		}
-		//if country name matches and mod is = 0
-		if wh.Country == country && calls[country]%wh.Calls == 0 {
-			jsonData, err := json.Marshal(wh)
-			if err != nil {
-				return err
-			}
-			_, err = http.Post(wh.URL, "application/json", bytes.NewBuffer(jsonData))
-			if err != nil {
-				return err
+		if err == nil {
+			if stored, ok := snapshot.Data()["calls"].(int64); ok {
+				count = stored
 			}
 		}
-	}
-	return nil
+		count++
+		return tx.Set(docRef, map[string]interface{}{"calls": count})
+	})
+	return count, err
 }
 
-// if the data is not found on the stack this function will be called to place it there
-func SetCachedData(ctx context.Context, cacheKey string, data []structures.DataEntry) error {
-	client, err := CreateFirestoreClient(ctx)
+func (s *Store) GetAllCalls(ctx context.Context) (map[string]int64, error) {
+	docs, err := s.client.Collection("calls").Documents(ctx).GetAll()
 	if err != nil {
-		return fmt.Errorf("Error creating Firestore client: %v", err)
+		return nil, err
 	}
-	defer client.Close()
+	calls := make(map[string]int64, len(docs))
+	for _, doc := range docs {
+		if count, ok := doc.Data()["calls"].(int64); ok {
+			calls[doc.Ref.ID] = count
+		}
+	}
+	return calls, nil
+}
 
+// if the data is not found on the stack this function will be called to place it there
+func (s *Store) SetCachedData(ctx context.Context, cacheKey string, data []structures.DataEntry) error {
 	cacheKey = strings.ToLower(cacheKey)
 
 	// uses the cache collection in the firestore project
-	cacheCollectionRef := client.Collection("cache")
+	cacheCollectionRef := s.client.Collection("cache")
 	// sets key
 	query := cacheCollectionRef.Where("key", "==", cacheKey).Limit(1)
 	iter := query.Documents(ctx)
+	defer iter.Stop()
 
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -168,20 +333,15 @@ func SetCachedData(ctx context.Context, cacheKey string, data []structures.DataE
 }
 
 // gets cached data
-func GetCachedData(ctx context.Context, cacheKey string) ([]structures.DataEntry, error) {
-	client, err := CreateFirestoreClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("Error creating Firestore client: %v", err)
-	}
-	defer client.Close()
-
+func (s *Store) GetCachedData(ctx context.Context, cacheKey string) (*structures.CacheEntry, error) {
 	cacheKey = strings.ToLower(cacheKey)
 
 	// from the cache collection
-	cacheCollectionRef := client.Collection("cache")
+	cacheCollectionRef := s.client.Collection("cache")
 	// where the key matches the cacheKey (user inputted) with a limit of 1
 	query := cacheCollectionRef.Where("key", "==", cacheKey).Limit(1)
 	iter := query.Documents(ctx)
+	defer iter.Stop()
 
 	doc, err := iter.Next()
 
@@ -194,9 +354,8 @@ func GetCachedData(ctx context.Context, cacheKey string) ([]structures.DataEntry
 	}
 
 	// updating cached data with incrementing hit count if found
-	docRef := doc.Ref
-	_, err = docRef.Update(ctx, []firestore.Update{
-		{Path: "hits", Value: doc.Data()["hits"].(int64) + 1},
+	_, err = doc.Ref.Update(ctx, []firestore.Update{
+		{Path: "hits", Value: firestore.Increment(1)},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("Error updating hit count in Firestore: %v", err)
@@ -209,21 +368,31 @@ func GetCachedData(ctx context.Context, cacheKey string) ([]structures.DataEntry
 	}
 
 	// Unmarshal the JSON string into a []structures.DataEntry
-	var data []structures.DataEntry
-	err = json.Unmarshal([]byte(jsonString), &data)
+	entry := structures.CacheEntry{Key: cacheKey}
+	err = json.Unmarshal([]byte(jsonString), &entry.Data)
 	if err != nil {
 		return nil, fmt.Errorf("Error unmarshaling JSON to DataEntry: %v", err)
 	}
 
-	return data, nil
+	// the stored values are from before the increment above
+	if hits, ok := doc.Data()["hits"].(int64); ok {
+		entry.Hits = hits + 1
+	}
+	if timestamp, ok := doc.Data()["timestamp"].(time.Time); ok {
+		entry.Timestamp = timestamp
+	}
+
+	return &entry, nil
 }
 
-// deletes caches older than a specific treshold
-func PurgeOldCacheEntries(ctx context.Context, client *firestore.Client, daysThreshold int) {
-	cacheCollectionRef := client.Collection("cache")
+// deletes caches older than a specific treshold, returns how many were deleted
+func (s *Store) PurgeOldCacheEntries(ctx context.Context, daysThreshold int) (int, error) {
+	cacheCollectionRef := s.client.Collection("cache")
 	query := cacheCollectionRef.Where("timestamp", "<", time.Now().AddDate(0, 0, -daysThreshold))
 	iter := query.Documents(ctx)
+	defer iter.Stop()
 
+	deleted := 0
 	for {
 		doc, err := iter.Next()
 		//iterated trough them all
@@ -231,16 +400,15 @@ func PurgeOldCacheEntries(ctx context.Context, client *firestore.Client, daysThr
 			break
 		}
 		if err != nil {
-			log.Printf("Error iterating Firestore documents: %v", err)
-			return
+			return deleted, fmt.Errorf("Error iterating Firestore documents: %v", err)
 		}
 
 		//delete the document
 		_, err = doc.Ref.Delete(ctx)
 		if err != nil {
-			log.Printf("Error deleting Firestore document: %v", err)
-		} else {
-			log.Printf("Treshold met, deleted document with ID: %s", doc.Ref.ID)
+			return deleted, fmt.Errorf("Error deleting Firestore document %s: %v", doc.Ref.ID, err)
 		}
+		deleted++
 	}
-}
\ No newline at end of file
+	return deleted, nil
+}
@@ -2,161 +2,122 @@ package firebase
 
 import (
 	"context"
-	"testing"
-	"net/http/httptest"
-	"net/http"
 	"os"
-	"time"
-	"strings"
 	"reflect"
+	"testing"
+	"time"
 
-	"github.com/stretchr/testify/assert"
 	"github.com/google/uuid"
 
 	"groupXX/structures"
 )
 
-func TestUpdateCalls(t *testing.T) {
-	// Create a new test server
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Assert that the correct URL was called
-		assert.Equal(t, "http://testwebhook.com", r.URL.String())
-	}))
+// opens a store against the firestore project, the tests are skipped when no credentials are available
+func newTestStore(t *testing.T, ctx context.Context) *Store {
+	credentials := os.Getenv("FIRESTORE_CREDENTIALS")
+	if credentials == "" {
+		credentials = structures.FIRESTORE_CREDENTIALS
+	}
+	if _, err := os.Stat(credentials); err != nil {
+		t.Skipf("No Firestore credentials available: %v", err)
+	}
 
-	// Ensure that the test server is closed after the test
-	defer ts.Close()
+	store, err := NewStore(ctx, structures.FIRESTORE_PROJECT, credentials)
+	if err != nil {
+		t.Fatalf("NewStore() returned an error: %v", err)
+	}
+	return store
+}
 
-	// Set the environment variable to the test server URL
-	os.Setenv("WEBHOOK_TEST_URL", ts.URL)
+func TestStoreWebhook(t *testing.T) {
+	// create a context with a 5-second timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// Call the function that we want to test
-	//ctx := context.Background()
-	err := UpdateCalls("germany")
-	assert.NoError(t, err)
-}
+	store := newTestStore(t, ctx)
+	defer store.Close()
 
-func TestStoreWebhooks(t *testing.T) {
-    // create a context with a 5-second timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-    defer cancel()
-
-    // create a new httptest server to simulate the API
-    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        w.WriteHeader(http.StatusCreated)
-    }))
-    defer srv.Close()
-
-    // create a new webhook to store
-    webhook := structures.Webhook{
-        URL:      uuid.NewString(),
-        Country: "germany",
-        Calls:   2,
-    }
-
-    // create a new Firestore client
-    client, err := CreateFirestoreClient(ctx)
-    if err != nil {
-        t.Fatalf("CreateFirestoreClient() returned an error: %v", err)
-    }
-    defer client.Close()
-
-    // store the webhook
-    _, err = StoreWebhooks(ctx, client, webhook)
-    if err != nil {
-        t.Fatalf("StoreWebhooks() returned an error: %v", err)
-    }
-
-    // retrieve the stored webhook
-    storedWebhook, err := GetWebhook(ctx, client, webhook.URL)
-    if err != nil {
-        t.Fatalf("GetWebhook() returned an error: %v", err)
-    }
-
-    // check if the stored webhook is the same as the original webhook
-    if !reflect.DeepEqual(storedWebhook, webhook) {
-        t.Errorf("Stored webhook %+v does not match original webhook %+v", storedWebhook, webhook)
-    }
-}
+	// create a new webhook to store
+	webhook := structures.Webhook{
+		URL:     uuid.NewString(),
+		Country: "germany",
+		Calls:   2,
+	}
+
+	// store the webhook
+	id, err := store.StoreWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("StoreWebhook() returned an error: %v", err)
+	}
+	defer store.DeleteWebhook(ctx, id)
+
+	// retrieve the stored webhook
+	storedWebhook, err := store.GetWebhook(ctx, id)
+	if err != nil {
+		t.Fatalf("GetWebhook() returned an error: %v", err)
+	}
 
-func TestGetWebhook(t *testing.T) {
-    // create a context with a 5-second timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-    defer cancel()
-
-    // create a new Firestore client
-    client, err := CreateFirestoreClient(ctx)
-    if err != nil {
-        t.Fatalf("CreateFirestoreClient() returned an error: %v", err)
-    }
-    defer client.Close()
-
-    // create a new webhook to store
-    webhook := structures.Webhook{
-        URL:      uuid.NewString(),
-        Country: "germany",
-        Calls:   2,
-    }
-
-    // store the webhook
-    _, err = StoreWebhooks(ctx, client, webhook)
-    if err != nil {
-        t.Fatalf("StoreWebhooks() returned an error: %v", err)
-    }
-
-    // get the stored webhook
-    storedWebhook, err := GetWebhook(ctx, client, webhook.URL)
-    if err != nil {
-        t.Fatalf("GetWebhook() returned an error: %v", err)
-    }
-
-    // check if the stored webhook is the same as the original webhook
-    if !reflect.DeepEqual(storedWebhook, webhook) {
-        t.Errorf("Stored webhook %+v does not match original webhook %+v", storedWebhook, webhook)
-    }
-
-    // delete the stored webhook
-    err = DeleteWebhook(ctx, client, webhook.URL)
-    if err != nil {
-        t.Fatalf("DeleteWebhook() returned an error: %v", err)
-    }
+	// check if the stored webhook is the same as the original webhook
+	if !reflect.DeepEqual(storedWebhook, webhook) {
+		t.Errorf("Stored webhook %+v does not match original webhook %+v", storedWebhook, webhook)
+	}
 }
 
 func TestGetWebhookNotFound(t *testing.T) {
-    // create a context with a 5-second timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-    defer cancel()
-
-    // create a new Firestore client
-    client, err := CreateFirestoreClient(ctx)
-    if err != nil {
-        t.Fatalf("CreateFirestoreClient() returned an error: %v", err)
-    }
-    defer client.Close()
-
-    // try to get a non-existent webhook
-    _, err = GetWebhook(ctx, client, "non-existent-url")
-    if !strings.Contains(err.Error(), "webhook not found") {
-        t.Fatalf("GetWebhook() returned unexpected error: %v", err)
-    }
+	// create a context with a 5-second timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := newTestStore(t, ctx)
+	defer store.Close()
+
+	// try to get a non-existent webhook
+	_, err := store.GetWebhook(ctx, "non-existent-id")
+	if err != structures.ErrWebhookNotFound {
+		t.Fatalf("GetWebhook() returned unexpected error: %v", err)
+	}
 }
 
 func TestDeleteWebhookNotFound(t *testing.T) {
-    // create a context with a 5-second timeout
-    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-    defer cancel()
-
-    // create a new Firestore client
-    client, err := CreateFirestoreClient(ctx)
-    if err != nil {
-        t.Fatalf("CreateFirestoreClient() returned an error: %v", err)
-    }
-    defer client.Close()
-
-    // try to delete a non-existent webhook
-    err = DeleteWebhook(ctx, client, "non-existent-url")
-    if !strings.Contains(err.Error(), "webhook not found") {
-        t.Fatalf("DeleteWebhook() returned unexpected error: %v", err)
-    }
+	// create a context with a 5-second timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := newTestStore(t, ctx)
+	defer store.Close()
+
+	// try to delete a non-existent webhook
+	err := store.DeleteWebhook(ctx, "non-existent-id")
+	if err != structures.ErrWebhookNotFound {
+		t.Fatalf("DeleteWebhook() returned unexpected error: %v", err)
+	}
+}
+
+func TestDeleteWebhookDeliveries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := newTestStore(t, ctx)
+	defer store.Close()
+
+	id, err := store.StoreWebhook(ctx, structures.Webhook{URL: uuid.NewString(), Country: "NOR", Calls: 2})
+	if err != nil {
+		t.Fatalf("StoreWebhook() returned an error: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if err := store.AddDelivery(ctx, structures.Delivery{WebhookID: id, Attempt: i, Time: time.Now()}); err != nil {
+			t.Fatalf("AddDelivery() returned an error: %v", err)
+		}
+	}
+
+	// the delivery attempts go with the webhook
+	if err := store.DeleteWebhook(ctx, id); err != nil {
+		t.Fatalf("DeleteWebhook() returned an error: %v", err)
+	}
+	deliveries, err := store.GetDeliveries(ctx, id)
+	if err != nil || len(deliveries) != 0 {
+		t.Errorf("GetDeliveries() of a deleted webhook returned %v, %v", deliveries, err)
+	}
 }
 
 func TestGetNumWebhooks(t *testing.T) {
@@ -164,12 +125,14 @@ func TestGetNumWebhooks(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// create a new Firestore client
-	client, err := CreateFirestoreClient(ctx)
+	store := newTestStore(t, ctx)
+	defer store.Close()
+
+	// the project is shared, so only the difference is checked
+	before, err := store.GetNumWebhooks(ctx)
 	if err != nil {
-		t.Fatalf("CreateFirestoreClient() returned an error: %v", err)
+		t.Fatalf("GetNumWebhooks() returned an error: %v", err)
 	}
-	defer client.Close()
 
 	// store some webhooks
 	webhooks := []structures.Webhook{
@@ -178,17 +141,88 @@ func TestGetNumWebhooks(t *testing.T) {
 		{URL: "webhook3", Country: "spain", Calls: 1},
 	}
 	for _, webhook := range webhooks {
-		if _, err := StoreWebhooks(ctx, client, webhook); err != nil {
-			t.Fatalf("StoreWebhooks() returned an error: %v", err)
+		id, err := store.StoreWebhook(ctx, webhook)
+		if err != nil {
+			t.Fatalf("StoreWebhook() returned an error: %v", err)
 		}
+		defer store.DeleteWebhook(ctx, id)
 	}
 
 	// check that the correct number of webhooks are returned
-	numWebhooks, err := GetNumWebhooks(ctx, client)
+	numWebhooks, err := store.GetNumWebhooks(ctx)
 	if err != nil {
 		t.Fatalf("GetNumWebhooks() returned an error: %v", err)
 	}
-	if numWebhooks != len(webhooks) {
-		t.Errorf("GetNumWebhooks() returned incorrect number of webhooks: got %d, want %d", numWebhooks, len(webhooks))
+	if numWebhooks-before != len(webhooks) {
+		t.Errorf("GetNumWebhooks() returned incorrect number of webhooks: got %d, want %d", numWebhooks-before, len(webhooks))
+	}
+}
+
+func TestSetWebhookState(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := newTestStore(t, ctx)
+	defer store.Close()
+
+	webhook := structures.Webhook{URL: uuid.NewString(), Country: "NOR", Event: structures.EVENT_THRESHOLD, Secret: "old"}
+	id, err := store.StoreWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("StoreWebhook() returned an error: %v", err)
+	}
+	defer store.DeleteWebhook(ctx, id)
+
+	// the secret changed after the state was read is kept
+	webhook.Secret = "new"
+	if err := store.UpdateWebhook(ctx, id, webhook); err != nil {
+		t.Fatalf("UpdateWebhook() returned an error: %v", err)
+	}
+	if err := store.SetWebhookState(ctx, id, structures.DIRECTION_ABOVE); err != nil {
+		t.Fatalf("SetWebhookState() returned an error: %v", err)
+	}
+	stored, err := store.GetWebhook(ctx, id)
+	if err != nil {
+		t.Fatalf("GetWebhook() returned an error: %v", err)
+	}
+	if stored.Secret != "new" || stored.LastState != structures.DIRECTION_ABOVE {
+		t.Errorf("Stored webhook %+v lost the secret or the state", stored)
+	}
+
+	if err := store.SetWebhookState(ctx, uuid.NewString(), structures.DIRECTION_ABOVE); err != structures.ErrWebhookNotFound {
+		t.Errorf("SetWebhookState() of a missing webhook returned %v, expected %v", err, structures.ErrWebhookNotFound)
+	}
+}
+
+func TestSetWebhookSecret(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := newTestStore(t, ctx)
+	defer store.Close()
+
+	webhook := structures.Webhook{URL: uuid.NewString(), Country: "NOR", Event: structures.EVENT_THRESHOLD, Secret: "old"}
+	id, err := store.StoreWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("StoreWebhook() returned an error: %v", err)
+	}
+	defer store.DeleteWebhook(ctx, id)
+
+	// the state set after the webhook was read for the patch is kept
+	if err := store.SetWebhookState(ctx, id, structures.DIRECTION_ABOVE); err != nil {
+		t.Fatalf("SetWebhookState() returned an error: %v", err)
+	}
+	if err := store.SetWebhookSecret(ctx, id, "new"); err != nil {
+		t.Fatalf("SetWebhookSecret() returned an error: %v", err)
+	}
+	stored, err := store.GetWebhook(ctx, id)
+	if err != nil {
+		t.Fatalf("GetWebhook() returned an error: %v", err)
+	}
+	if stored.Secret != "new" || stored.LastState != structures.DIRECTION_ABOVE {
+		t.Errorf("Stored webhook %+v lost the secret or the state", stored)
+	}
+
+	if err := store.SetWebhookSecret(ctx, uuid.NewString(), "new"); err != structures.ErrWebhookNotFound {
+		t.Errorf("SetWebhookSecret() of a missing webhook returned %v, expected %v", err, structures.ErrWebhookNotFound)
 	}
 }
@@ -0,0 +1,84 @@
+package functions
+
+import (
+	"net/http"
+	"sort"
+
+	"groupXX/structures"
+)
+
+// computes a metric for a group of countries for every year from begin to end, the latest year of the
+// metric if neither is given. Without a weight it is the mean of the countries with a value for the year,
+// with a weight metric like population each country counts by its value of that metric for the year
+func Aggregate(name string, members []string, metric string, weight string, begin *int, end *int) ([]structures.AggregateEntry, error) {
+	loaded := CurrentData()
+	if loaded == nil {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No data loaded")
+	}
+	values, ok := loaded.Metric(metric)
+	if !ok {
+		return nil, NewError(http.StatusBadRequest, structures.ERR_UNKNOWN_METRIC, "Unknown metric "+metric)
+	}
+	weights := values
+	if weight != "" {
+		weights, ok = loaded.Metric(weight)
+		if !ok {
+			return nil, NewError(http.StatusBadRequest, structures.ERR_UNKNOWN_METRIC, "Unknown weight metric "+weight)
+		}
+	}
+
+	if begin == nil && end == nil {
+		latestYear := values.LatestYear()
+		begin, end = &latestYear, &latestYear
+	}
+
+	type sum struct {
+		values    float64
+		weights   float64
+		countries int
+	}
+	byYear := make(map[int]*sum)
+	for _, member := range members {
+		//the weight of the country for each year, a year without a weight leaves the country out
+		memberWeights := make(map[int]float64)
+		if weight != "" {
+			for _, entry := range weights.Range(member, begin, end) {
+				memberWeights[entry.Year] = entry.Percentage
+			}
+		}
+
+		for _, entry := range values.Range(member, begin, end) {
+			w := 1.0
+			if weight != "" {
+				var found bool
+				w, found = memberWeights[entry.Year]
+				if !found || w <= 0 {
+					continue
+				}
+			}
+			s, ok := byYear[entry.Year]
+			if !ok {
+				s = &sum{}
+				byYear[entry.Year] = s
+			}
+			s.values += entry.Percentage * w
+			s.weights += w
+			s.countries++
+		}
+	}
+
+	var aggregates []structures.AggregateEntry
+	for year, s := range byYear {
+		aggregates = append(aggregates, structures.AggregateEntry{
+			Name:      name,
+			Year:      year,
+			Metric:    values.MetricName(),
+			Value:     s.values / s.weights,
+			Weight:    weight,
+			Countries: s.countries,
+			Members:   len(members),
+		})
+	}
+	sort.Slice(aggregates, func(i, j int) bool { return aggregates[i].Year < aggregates[j].Year })
+	return aggregates, nil
+}
@@ -0,0 +1,53 @@
+package functions
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/structures"
+)
+
+func TestAggregate(t *testing.T) {
+	defaultData := CurrentData()
+	defer SetData(defaultData)
+	nan := math.NaN()
+	SetData(dataset.FromTable(&dataset.Table{
+		Rows: []structures.DataEntry{
+			{Country: "Norway", CountryCode: "NOR", Year: 2020},
+			{Country: "Norway", CountryCode: "NOR", Year: 2021},
+			{Country: "Sweden", CountryCode: "SWE", Year: 2020},
+			{Country: "Sweden", CountryCode: "SWE", Year: 2021},
+			{Country: "Denmark", CountryCode: "DNK", Year: 2021},
+		},
+		Columns: map[string][]float64{
+			structures.DEFAULTMETRIC: {70, 72, 50, 52, 40},
+			"population":             {5, 5, 10, nan, 6},
+		},
+	}))
+	members := []string{"NOR", "SWE", "DNK", "ATL"}
+
+	//the latest year, unweighted
+	aggregates, err := Aggregate("Nordics", members, structures.DEFAULTMETRIC, "", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.AggregateEntry{{Name: "Nordics", Year: 2021, Metric: structures.DEFAULTMETRIC,
+		Value: (72.0 + 52 + 40) / 3, Countries: 3, Members: 4}}, aggregates)
+
+	//weighted, where Sweden has no population for 2021 and is left out of that year
+	begin, end := 2020, 2021
+	aggregates, err = Aggregate("Nordics", members, structures.DEFAULTMETRIC, "population", &begin, &end)
+	assert.NoError(t, err)
+	assert.Len(t, aggregates, 2)
+	assert.Equal(t, 2020, aggregates[0].Year)
+	assert.InDelta(t, (70.0*5+50*10)/15, aggregates[0].Value, 1e-9)
+	assert.Equal(t, 2, aggregates[0].Countries)
+	assert.InDelta(t, (72.0*5+40*6)/11, aggregates[1].Value, 1e-9)
+	assert.Equal(t, "population", aggregates[1].Weight)
+
+	_, err = Aggregate("Nordics", members, "wind_share_energy", "", nil, nil)
+	assert.Error(t, err)
+	_, err = Aggregate("Nordics", members, structures.DEFAULTMETRIC, "gdp", nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package functions
+
+import (
+	"strings"
+	"unicode"
+
+	"groupXX/structures"
+)
+
+//the first letter binary search tree the data was looked up in before the dataset, kept as the baseline of the
+//benchmarks in retrieval_test.go
+
+//structure of the binary search three
+type BSTNode struct {
+	Data   []structures.DataEntry
+	Letter rune
+	Left   *BSTNode
+	Right  *BSTNode
+}
+
+// searches the binary tree based on letter input
+func SearchBST(node *BSTNode, letter rune) []structures.DataEntry {
+	//if node doesn't exist
+	if node == nil {
+		return nil
+	}
+	//go left is letter is smaller, right for bigger and do recusive call
+	if letter < node.Letter {
+		return SearchBST(node.Left, letter)
+	} else if letter > node.Letter {
+		return SearchBST(node.Right, letter)
+	}
+	return node.Data
+}
+
+// extracts the entries with given key
+func ExtractEntriesWithKey(partitionedData map[rune][]structures.DataEntry, key rune) []structures.DataEntry {
+	//takes to uppercode so regardless of the case of user input matches database
+	upperKey := unicode.ToUpper(key)
+
+	//creates storage for entries
+	entries := make([]structures.DataEntry, 0)
+	if upperData, ok := partitionedData[upperKey]; ok {
+		//appends with that key
+		entries = append(entries, upperData...)
+	}
+
+	return entries
+}
+
+func FindMatchingCountries(data []structures.DataEntry, country string) []structures.DataEntry {
+	//storage for matching countries
+	matchingCountries := make([]structures.DataEntry, 0)
+	countryLower := strings.ToLower(country)
+
+	//compares on a lowercase level so the cases is no problem
+	for _, entry := range data {
+		var entryCountryLower string
+		//the old lookup took any three letters for a code
+		if len(countryLower) == 3 {
+			entryCountryLower = strings.ToLower(entry.CountryCode)
+		} else {
+			entryCountryLower = strings.ToLower(entry.Country)
+		}
+		if countryLower == entryCountryLower {
+			matchingCountries = append(matchingCountries, entry)
+		}
+	}
+	if len(matchingCountries) == 0 {
+		return nil
+	}
+
+	return matchingCountries
+}
+
+// based on lists of DataEntry structs returns them mapped into structured form
+func PartitionDataByFirstLetter(data []structures.DataEntry) *BSTNode {
+	//sets root
+	var root *BSTNode
+
+	for _, entry := range data {
+		//retrieves first letter
+		firstLetter := unicode.ToUpper(rune(entry.Country[0]))
+		//calls inserIntoBST for each map
+		root = InsertIntoBST(root, firstLetter, entry)
+	}
+	return root
+}
+
+// take node and letter
+func InsertIntoBST(node *BSTNode, letter rune, entry structures.DataEntry) *BSTNode {
+	//if node is nil return the BST node with it's data
+	if node == nil {
+		return &BSTNode{Data: []structures.DataEntry{entry}, Letter: letter}
+	}
+	//left for less right for greater else become root
+	if letter < node.Letter {
+		node.Left = InsertIntoBST(node.Left, letter, entry)
+	} else if letter > node.Letter {
+		node.Right = InsertIntoBST(node.Right, letter, entry)
+	} else {
+		node.Data = append(node.Data, entry)
+	}
+	return node
+}
@@ -0,0 +1,63 @@
+package functions
+
+import (
+	"sort"
+
+	"groupXX/structures"
+)
+
+// aligns the series of several countries, each sorted by year as ReadCountryInfo returns them, on the years any
+// of them has a value for. The countries give the name and code of each series, which may be empty. If baseline
+// is the index of one of the series, every series gets the difference to it
+func Compare(countries []structures.DataEntry, series [][]structures.DataEntry, metric string, baseline int) structures.Comparison {
+	yearSet := make(map[int]bool)
+	for _, data := range series {
+		for _, entry := range data {
+			yearSet[entry.Year] = true
+		}
+	}
+	years := make([]int, 0, len(yearSet))
+	for year := range yearSet {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	comparison := structures.Comparison{Metric: metric, Years: years, Countries: make([]structures.ComparedSeries, len(series))}
+	for i, data := range series {
+		byYear := make(map[int]float64, len(data))
+		for _, entry := range data {
+			byYear[entry.Year] = entry.Percentage
+		}
+		compared := structures.ComparedSeries{
+			Country:     countries[i].Country,
+			CountryCode: countries[i].CountryCode,
+			Values:      make([]*float64, len(years)),
+			Missing:     make([]int, 0),
+		}
+		for j, year := range years {
+			if value, ok := byYear[year]; ok {
+				compared.Values[j] = &value
+			} else {
+				compared.Missing = append(compared.Missing, year)
+			}
+		}
+		comparison.Countries[i] = compared
+	}
+
+	if baseline < 0 || baseline >= len(series) {
+		return comparison
+	}
+	base := comparison.Countries[baseline]
+	comparison.Baseline = base.CountryCode
+	for i := range comparison.Countries {
+		compared := &comparison.Countries[i]
+		compared.Difference = make([]*float64, len(years))
+		for j := range years {
+			if compared.Values[j] != nil && base.Values[j] != nil {
+				difference := *compared.Values[j] - *base.Values[j]
+				compared.Difference[j] = &difference
+			}
+		}
+	}
+	return comparison
+}
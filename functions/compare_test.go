@@ -0,0 +1,45 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/structures"
+)
+
+func TestCompare(t *testing.T) {
+	norway := []structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2019, Percentage: 70},
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 71},
+	}
+	sweden := []structures.DataEntry{
+		{Country: "Sweden", CountryCode: "SWE", Year: 2020, Percentage: 50},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 52},
+	}
+	countries := []structures.DataEntry{{Country: "Norway", CountryCode: "NOR"}, {Country: "Sweden", CountryCode: "SWE"},
+		{Country: "Atlantis", CountryCode: "ATL"}}
+
+	comparison := Compare(countries, [][]structures.DataEntry{norway, sweden, nil}, structures.DEFAULTMETRIC, 0)
+	assert.Equal(t, []int{2019, 2020, 2021}, comparison.Years)
+	assert.Equal(t, "NOR", comparison.Baseline)
+	assert.Len(t, comparison.Countries, 3)
+
+	swe := comparison.Countries[1]
+	assert.Equal(t, "Sweden", swe.Country)
+	assert.Nil(t, swe.Values[0])
+	assert.Equal(t, 50.0, *swe.Values[1])
+	assert.Equal(t, []int{2019}, swe.Missing)
+	assert.Nil(t, swe.Difference[0])
+	assert.Equal(t, -21.0, *swe.Difference[1])
+	assert.Nil(t, swe.Difference[2])
+	assert.Equal(t, 0.0, *comparison.Countries[0].Difference[0])
+
+	//a country without values is listed with every year missing
+	assert.Equal(t, "ATL", comparison.Countries[2].CountryCode)
+	assert.Equal(t, []int{2019, 2020, 2021}, comparison.Countries[2].Missing)
+
+	comparison = Compare(countries[:2], [][]structures.DataEntry{norway, sweden}, structures.DEFAULTMETRIC, -1)
+	assert.Empty(t, comparison.Baseline)
+	assert.Nil(t, comparison.Countries[0].Difference)
+}
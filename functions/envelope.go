@@ -0,0 +1,40 @@
+package functions
+
+import (
+	"path/filepath"
+	"time"
+
+	"groupXX/structures"
+)
+
+// returns the first and last index (exclusive) of a page of total entries, and how many pages there are.
+// Pages start at 1, there is always at least one page and a page after the last one is empty
+func Paginate(total int, page int, limit int) (int, int, int) {
+	pages := (total + limit - 1) / limit
+	if pages == 0 {
+		pages = 1
+	}
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	return start, end, pages
+}
+
+// metadata of a list of count entries out of total, with the file and version of the data in use
+func NewMeta(count int, total int) structures.Meta {
+	meta := structures.Meta{
+		Count:       count,
+		Total:       total,
+		Source:      filepath.Base(DataPath),
+		GeneratedAt: time.Now().UTC(),
+	}
+	if data := CurrentData(); data != nil {
+		meta.Version = data.Version()
+	}
+	return meta
+}
@@ -0,0 +1,46 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/structures"
+)
+
+func TestPaginate(t *testing.T) {
+	testCases := []struct {
+		total, page, limit int
+		start, end, pages  int
+	}{
+		{total: 250, page: 1, limit: 100, start: 0, end: 100, pages: 3},
+		{total: 250, page: 3, limit: 100, start: 200, end: 250, pages: 3},
+		{total: 250, page: 4, limit: 100, start: 250, end: 250, pages: 3},
+		{total: 200, page: 2, limit: 100, start: 100, end: 200, pages: 2},
+		{total: 0, page: 1, limit: 100, start: 0, end: 0, pages: 1},
+	}
+	for _, tc := range testCases {
+		start, end, pages := Paginate(tc.total, tc.page, tc.limit)
+		assert.Equal(t, []int{tc.start, tc.end, tc.pages}, []int{start, end, pages})
+	}
+}
+
+func TestNewMeta(t *testing.T) {
+	defaultData, defaultPath := CurrentData(), DataPath
+	defer func() {
+		SetData(defaultData)
+		DataPath = defaultPath
+	}()
+	data := dataset.New([]structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6}})
+	SetData(data)
+	DataPath = "/srv/data/energyData.csv"
+
+	meta := NewMeta(1, 3)
+	assert.Equal(t, 1, meta.Count)
+	assert.Equal(t, 3, meta.Total)
+	assert.Equal(t, "energyData.csv", meta.Source)
+	assert.Equal(t, data.Version(), meta.Version)
+	assert.False(t, meta.GeneratedAt.IsZero())
+	assert.Zero(t, meta.Page)
+}
@@ -0,0 +1,67 @@
+package functions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"groupXX/structures"
+)
+
+// creates an error with the status to respond with and a stable code
+func NewError(status int, code string, message string) *structures.APIError {
+	return &structures.APIError{Status: status, Code: code, Message: message}
+}
+
+// same as NewError, with details for the user like the suggestions of a search
+func NewErrorWithDetails(status int, code string, message string, details interface{}) *structures.APIError {
+	return &structures.APIError{Status: status, Code: code, Message: message, Details: details}
+}
+
+// error for a method a handler doesn't support, with the methods it does support in the Allow header
+func MethodNotSupported(method string, supported ...string) *structures.APIError {
+	allowed := strings.Join(supported, ", ")
+	err := NewError(http.StatusMethodNotAllowed, structures.ERR_METHOD_NOT_SUPPORTED,
+		"REST Method '"+method+"' not supported. Currently only '"+allowed+"' are supported.")
+	err.Allow = allowed
+	return err
+}
+
+// writes the error as application/problem+json, errors which aren't a structures.APIError are logged and written as
+// an internal error so nothing internal is shown to the user. The request is only used for the instance and may be nil
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *structures.APIError
+	if !errors.As(err, &apiErr) {
+		log.Printf("Internal error: %v", err)
+		apiErr = NewError(http.StatusInternalServerError, structures.ERR_INTERNAL, "Internal server error")
+	}
+
+	problem := structures.Problem{
+		Type:    "about:blank",
+		Title:   http.StatusText(apiErr.Status),
+		Status:  apiErr.Status,
+		Detail:  apiErr.Message,
+		Code:    apiErr.Code,
+		Details: apiErr.Details,
+	}
+	if r != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	encoded, err := json.Marshal(problem)
+	if err != nil {
+		//the details couldn't be encoded, so the problem is written without them
+		log.Printf("Error encoding error response: %v", err)
+		problem.Details = nil
+		encoded, _ = json.Marshal(problem)
+	}
+	if apiErr.Allow != "" {
+		w.Header().Set("Allow", apiErr.Allow)
+	}
+	w.Header().Set("Content-Type", structures.PROBLEM_CONTENT_TYPE)
+	w.WriteHeader(apiErr.Status)
+	fmt.Fprintln(w, string(encoded))
+}
@@ -0,0 +1,57 @@
+package functions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/structures"
+)
+
+func TestWriteError(t *testing.T) {
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"nor", nil)
+	WriteError(rr, r, NewErrorWithDetails(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER, "Bad year",
+		map[string]string{"year": "abc"}))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, structures.PROBLEM_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Bad year",
+		"instance":"/energy/v1/renewables/current/nor","code":"invalid_parameter","details":{"year":"abc"}}`, rr.Body.String())
+
+	//a wrapped error keeps its status and code
+	rr = httptest.NewRecorder()
+	WriteError(rr, r, fmt.Errorf("reading: %w", NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No data loaded")))
+	problem := structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusServiceUnavailable, problem.Status)
+	assert.Equal(t, structures.ERR_UNAVAILABLE, problem.Code)
+
+	//other errors are internal errors, without showing what went wrong
+	rr = httptest.NewRecorder()
+	WriteError(rr, nil, fmt.Errorf("connection refused to 10.0.0.1"))
+	problem = structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, structures.ERR_INTERNAL, problem.Code)
+	assert.NotContains(t, problem.Detail, "10.0.0.1")
+	assert.Empty(t, problem.Instance)
+}
+
+func TestMethodNotSupported(t *testing.T) {
+	err := MethodNotSupported(http.MethodPut, http.MethodGet)
+	assert.Equal(t, http.StatusMethodNotAllowed, err.Status)
+	assert.Equal(t, "REST Method 'PUT' not supported. Currently only 'GET' are supported.", err.Message)
+
+	err = MethodNotSupported(http.MethodPut, http.MethodGet, http.MethodPatch, http.MethodPost)
+	assert.Equal(t, "REST Method 'PUT' not supported. Currently only 'GET, PATCH, POST' are supported.", err.Message)
+
+	//the supported methods are sent in the Allow header
+	rr := httptest.NewRecorder()
+	WriteError(rr, nil, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "GET, PATCH, POST", rr.Header().Get("Allow"))
+}
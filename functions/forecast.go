@@ -0,0 +1,118 @@
+package functions
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"groupXX/structures"
+)
+
+// projects the percentages of one country, sorted by year as ReadCountryInfo returns them, for every year after
+// the last one until the given year. The observed years come first, followed by the projected ones with their
+// 95% prediction interval. Percentages are kept between 0 and 100
+func Forecast(data []structures.DataEntry, until int, model string) ([]structures.ForecastEntry, error) {
+	if len(data) < 3 {
+		return nil, NewError(http.StatusBadRequest, structures.ERR_INSUFFICIENT_DATA,
+			"At least three years are needed for a forecast, found "+strconv.Itoa(len(data)))
+	}
+	last := data[len(data)-1]
+	if until <= last.Year {
+		return nil, NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"The forecast has to end after the last year in the data, "+strconv.Itoa(last.Year))
+	}
+
+	var project func(step int) (value float64, spread float64)
+	switch model {
+	case structures.FORECAST_LINEAR:
+		project = linearForecast(data)
+	case structures.FORECAST_HOLT:
+		project = holtForecast(data)
+	default:
+		return nil, NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER, "Unknown forecast model "+model)
+	}
+
+	forecast := make([]structures.ForecastEntry, 0, len(data)+until-last.Year)
+	for _, entry := range data {
+		forecast = append(forecast, structures.ForecastEntry{DataEntry: entry})
+	}
+	for year := last.Year + 1; year <= until; year++ {
+		value, spread := project(year - last.Year)
+		lower, upper := clampPercentage(value-spread), clampPercentage(value+spread)
+		forecast = append(forecast, structures.ForecastEntry{
+			DataEntry: structures.DataEntry{
+				Country:     last.Country,
+				CountryCode: last.CountryCode,
+				Year:        year,
+				Percentage:  clampPercentage(value),
+			},
+			Projected: true,
+			Lower:     &lower,
+			Upper:     &upper,
+		})
+	}
+	return forecast, nil
+}
+
+// the least squares line through the years, where the interval grows with the distance from the mean year
+func linearForecast(data []structures.DataEntry) func(step int) (float64, float64) {
+	l := fitLine(data)
+	lastYear := data[len(data)-1].Year
+	//standard error of the residuals
+	sse := l.syy - l.slope*l.sxy
+	s := math.Sqrt(math.Max(sse, 0) / (l.n - 2))
+	return func(step int) (float64, float64) {
+		year := lastYear + step
+		dx := float64(year) - l.meanX
+		leverage := 1 / l.n
+		if l.sxx > 0 {
+			leverage += dx * dx / l.sxx
+		}
+		return l.at(year), structures.FORECASTZ * s * math.Sqrt(1+leverage)
+	}
+}
+
+// Holt's linear exponential smoothing, with the smoothing of the level and trend that gives the lowest squared
+// error of the forecasts one year ahead. The years are taken as consecutive, a missing year is skipped
+func holtForecast(data []structures.DataEntry) func(step int) (float64, float64) {
+	var bestAlpha, bestBeta, bestLevel, bestTrend float64
+	bestSSE := math.Inf(1)
+	for a := 1; a <= 9; a++ {
+		for b := 1; b <= 9; b++ {
+			alpha, beta := float64(a)/10, float64(b)/10
+			level, trend, sse := holtSmooth(data, alpha, beta)
+			if sse < bestSSE {
+				bestAlpha, bestBeta, bestLevel, bestTrend, bestSSE = alpha, beta, level, trend, sse
+			}
+		}
+	}
+
+	//variance of the forecasts one year ahead. The second year is always forecast exactly, as the first trend
+	//is taken from it, so only the years after count
+	sigma := math.Sqrt(bestSSE / float64(len(data)-2))
+	return func(step int) (float64, float64) {
+		variance := 1.0
+		for j := 1; j < step; j++ {
+			variance += math.Pow(bestAlpha*(1+float64(j)*bestBeta), 2)
+		}
+		return bestLevel + float64(step)*bestTrend, structures.FORECASTZ * sigma * math.Sqrt(variance)
+	}
+}
+
+// smooths the series and returns the last level and trend, and the sum of the squared errors one year ahead
+func holtSmooth(data []structures.DataEntry, alpha float64, beta float64) (float64, float64, float64) {
+	level, trend := data[0].Percentage, data[1].Percentage-data[0].Percentage
+	var sse float64
+	for _, entry := range data[1:] {
+		err := entry.Percentage - (level + trend)
+		sse += err * err
+		previous := level
+		level = alpha*entry.Percentage + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+	}
+	return level, trend, sse
+}
+
+func clampPercentage(value float64) float64 {
+	return math.Min(math.Max(value, 0), 100)
+}
@@ -0,0 +1,63 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/structures"
+)
+
+func TestForecast(t *testing.T) {
+	//a straight line from 40 in 2018 rising 5 a year
+	var data []structures.DataEntry
+	for year := 2018; year <= 2021; year++ {
+		data = append(data, structures.DataEntry{Country: "Norway", CountryCode: "NOR", Year: year,
+			Percentage: 40 + 5*float64(year-2018)})
+	}
+
+	for _, model := range []string{structures.FORECAST_LINEAR, structures.FORECAST_HOLT} {
+		forecast, err := Forecast(data, 2024, model)
+		assert.NoError(t, err, model)
+		assert.Len(t, forecast, 7, model)
+		assert.False(t, forecast[3].Projected, model)
+		assert.Nil(t, forecast[3].Lower, model)
+		assert.Equal(t, structures.DataEntry{Country: "Norway", CountryCode: "NOR", Year: 2022, Percentage: 60},
+			roundEntry(forecast[4].DataEntry), model)
+		assert.True(t, forecast[4].Projected, model)
+		assert.InDelta(t, 70, forecast[6].Percentage, 1e-9, model)
+		//a perfect fit leaves no uncertainty
+		assert.InDelta(t, 70, *forecast[6].Lower, 1e-9, model)
+	}
+
+	//a noisy series has an interval which widens with the years
+	noisy := []structures.DataEntry{{Year: 2018, Percentage: 50}, {Year: 2019, Percentage: 53}, {Year: 2020, Percentage: 51},
+		{Year: 2021, Percentage: 55}}
+	for _, model := range []string{structures.FORECAST_LINEAR, structures.FORECAST_HOLT} {
+		forecast, err := Forecast(noisy, 2030, model)
+		assert.NoError(t, err, model)
+		first, final := forecast[4], forecast[len(forecast)-1]
+		assert.Less(t, *first.Lower, first.Percentage, model)
+		assert.Greater(t, *final.Upper-*final.Lower, *first.Upper-*first.Lower, model)
+	}
+
+	//percentages stay below 100
+	high := []structures.DataEntry{{Year: 2018, Percentage: 80}, {Year: 2019, Percentage: 90}, {Year: 2020, Percentage: 86},
+		{Year: 2021, Percentage: 97}}
+	forecast, err := Forecast(high, 2030, structures.FORECAST_LINEAR)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, forecast[len(forecast)-1].Percentage)
+	assert.Equal(t, 100.0, *forecast[len(forecast)-1].Upper)
+
+	_, err = Forecast(data[:2], 2030, structures.FORECAST_LINEAR)
+	assert.Error(t, err)
+	_, err = Forecast(data, 2021, structures.FORECAST_LINEAR)
+	assert.Error(t, err)
+	_, err = Forecast(data, 2030, "arima")
+	assert.Error(t, err)
+}
+
+func roundEntry(entry structures.DataEntry) structures.DataEntry {
+	entry.Percentage = float64(int(entry.Percentage*1e6+0.5)) / 1e6
+	return entry
+}
@@ -0,0 +1,343 @@
+package functions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"groupXX/structures"
+)
+
+// media types of the Accept header and the format they are answered with
+var acceptedTypes = map[string]string{
+	structures.JSON_CONTENT_TYPE:   structures.FORMAT_JSON,
+	structures.CSV_CONTENT_TYPE:    structures.FORMAT_CSV,
+	structures.NDJSON_CONTENT_TYPE: structures.FORMAT_NDJSON,
+	"application/ndjson":           structures.FORMAT_NDJSON,
+	"application/jsonl":            structures.FORMAT_NDJSON,
+	structures.XLSX_CONTENT_TYPE:   structures.FORMAT_XLSX,
+	"text/*":                       structures.FORMAT_CSV,
+	"application/*":                structures.FORMAT_JSON,
+	"*/*":                          structures.FORMAT_JSON,
+}
+
+// picks the format of a response from ?format= and otherwise from the Accept header, JSON if neither is given.
+// An unknown ?format= is a 400, and an Accept header without any type the service has is a 406
+func NegotiateFormat(format string, accept string) (string, error) {
+	if format != "" {
+		format = strings.ToLower(format)
+		switch format {
+		case structures.FORMAT_JSON, structures.FORMAT_CSV, structures.FORMAT_NDJSON, structures.FORMAT_XLSX:
+			return format, nil
+		}
+		return "", NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER, "Error parsing format value, expected '"+
+			structures.FORMAT_JSON+"', '"+structures.FORMAT_CSV+"', '"+structures.FORMAT_NDJSON+"' or '"+structures.FORMAT_XLSX+"'")
+	}
+	if strings.TrimSpace(accept) == "" {
+		return structures.FORMAT_JSON, nil
+	}
+
+	//the media types by their quality, the first one the service has is used
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		accepted := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if quality, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					accepted.quality = quality
+				}
+			}
+		}
+		if accepted.quality > 0 {
+			ranges = append(ranges, accepted)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	for _, accepted := range ranges {
+		if format, ok := acceptedTypes[accepted.mediaType]; ok {
+			return format, nil
+		}
+	}
+	return "", NewError(http.StatusNotAcceptable, structures.ERR_NOT_ACCEPTABLE, "None of the accepted types '"+accept+
+		"' are available, expected "+structures.JSON_CONTENT_TYPE+", "+structures.CSV_CONTENT_TYPE+", "+
+		structures.NDJSON_CONTENT_TYPE+" or "+structures.XLSX_CONTENT_TYPE)
+}
+
+// outputs data in the format, JSON as PrintData does. The other formats have a row or line per entry of a list,
+// and the warnings of a neighbour response are sent as Warning headers since the rows have no place for them
+func PrintFormat(w http.ResponseWriter, data interface{}, format string) {
+	if format == structures.FORMAT_JSON || format == "" {
+		w.Header().Set("Content-Type", structures.JSON_CONTENT_TYPE)
+		PrintData(w, data)
+		return
+	}
+	if response, ok := data.(structures.NeighbourResponse); ok {
+		for _, warning := range response.Warnings {
+			w.Header().Add("Warning", "199 - "+strconv.Quote(warning))
+		}
+		data = response.Entries
+	}
+
+	if format == structures.FORMAT_NDJSON {
+		w.Header().Set("Content-Type", structures.NDJSON_CONTENT_TYPE)
+		if err := WriteNDJSON(w, data); err != nil {
+			//the response has already started, so the error can only be logged
+			log.Printf("Error writing ndjson response: %v", err)
+		}
+		return
+	}
+
+	header, rows, ok := Table(data)
+	if !ok {
+		WriteError(w, nil, NewError(http.StatusNotAcceptable, structures.ERR_NOT_ACCEPTABLE,
+			"The response can't be written as "+format))
+		return
+	}
+	var err error
+	if format == structures.FORMAT_CSV {
+		w.Header().Set("Content-Type", structures.CSV_CONTENT_TYPE+"; charset=utf-8")
+		err = WriteCSV(w, header, rows)
+	} else {
+		w.Header().Set("Content-Type", structures.XLSX_CONTENT_TYPE)
+		w.Header().Set("Content-Disposition", "attachment; filename=\"energy.xlsx\"")
+		err = WriteXLSX(w, header, rows)
+	}
+	if err != nil {
+		log.Printf("Error writing %s response: %v", format, err)
+	}
+}
+
+// writes every entry of a list as a line of JSON, flushed as it goes so large lists are streamed.
+// Anything else than a list is written as one line
+func WriteNDJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	list := reflect.ValueOf(data)
+	if list.Kind() != reflect.Slice {
+		return encoder.Encode(data)
+	}
+	flusher, _ := w.(http.Flusher)
+	for i := 0; i < list.Len(); i++ {
+		if err := encoder.Encode(list.Index(i).Interface()); err != nil {
+			return err
+		}
+		if flusher != nil && (i+1)%structures.NDJSONFLUSHLINES == 0 {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
+
+// writes the header and rows as csv, with numbers written as they are in the data file
+func WriteCSV(w io.Writer, header []string, rows [][]interface{}) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			record[i] = formatCell(cell)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatCell(cell interface{}) string {
+	switch value := cell.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// header and rows of the lists the endpoints respond with, in the columns of energyData.csv (Entity, Code, Year
+// and the value of the metric) followed by the other fields of the entries. A trend is one row, and a comparison
+// has a row per country and year. Returns false for anything else
+func Table(data interface{}) ([]string, [][]interface{}, bool) {
+	var header []string
+	var rows [][]interface{}
+	switch entries := data.(type) {
+	case []structures.DataEntry:
+		header = dataHeader(structures.DEFAULTMETRIC)
+		for _, entry := range entries {
+			rows = append(rows, dataRow(entry))
+		}
+	case []structures.NeighbourEntry:
+		header = append(dataHeader(structures.DEFAULTMETRIC), "distance")
+		for _, entry := range entries {
+			rows = append(rows, append(dataRow(entry.DataEntry), entry.Distance))
+		}
+	case []structures.CurrentEntry:
+		header = append(dataHeader(structures.DEFAULTMETRIC), stalenessHeader...)
+		withDistance := len(entries) > 0 && entries[0].Distance != nil
+		if withDistance {
+			header = append(header, "distance")
+		}
+		for _, entry := range entries {
+			row := append(dataRow(entry.DataEntry), stalenessRow(entry.Staleness)...)
+			if withDistance {
+				row = append(row, distanceCell(entry.Distance))
+			}
+			rows = append(rows, row)
+		}
+	case []structures.MetricEntry:
+		metric := "value"
+		if len(entries) > 0 {
+			metric = entries[0].Metric
+		}
+		header = dataHeader(metric)
+		withDistance := len(entries) > 0 && entries[0].Distance != nil
+		if withDistance {
+			header = append(header, "distance")
+		}
+		for _, entry := range entries {
+			row := metricRow(entry)
+			if withDistance {
+				row = append(row, distanceCell(entry.Distance))
+			}
+			rows = append(rows, row)
+		}
+	case []structures.CurrentMetricEntry:
+		metric := "value"
+		if len(entries) > 0 {
+			metric = entries[0].Metric
+		}
+		header = append(dataHeader(metric), stalenessHeader...)
+		withDistance := len(entries) > 0 && entries[0].Distance != nil
+		if withDistance {
+			header = append(header, "distance")
+		}
+		for _, entry := range entries {
+			row := append(metricRow(entry.MetricEntry), stalenessRow(entry.Staleness)...)
+			if withDistance {
+				row = append(row, distanceCell(entry.Distance))
+			}
+			rows = append(rows, row)
+		}
+	case []structures.AggregateEntry:
+		//an aggregate is of a region or group of countries, so it has no code
+		metric := "value"
+		if len(entries) > 0 {
+			metric = entries[0].Metric
+		}
+		header = append(dataHeader(metric), "weight", "countries", "members")
+		for _, entry := range entries {
+			rows = append(rows, []interface{}{entry.Name, "", entry.Year, entry.Value, entry.Weight, entry.Countries, entry.Members})
+		}
+	case []structures.RankEntry:
+		header = append(dataHeader(structures.DEFAULTMETRIC), "rank", "percentile", "previousRank", "rankChange")
+		for _, entry := range entries {
+			rows = append(rows, []interface{}{entry.Country, entry.CountryCode, entry.Year, entry.Percentage, entry.Rank,
+				entry.Percentile, distanceCell(entry.PreviousRank), distanceCell(entry.RankChange)})
+		}
+	case []structures.ForecastEntry:
+		header = append(dataHeader(structures.DEFAULTMETRIC), "projected", "lower", "upper")
+		for _, entry := range entries {
+			rows = append(rows, append(dataRow(entry.DataEntry), entry.Projected, valueCell(entry.Lower), valueCell(entry.Upper)))
+		}
+	case []structures.Mix:
+		//a column per source, empty for the sources a year has no share for
+		header = []string{"Entity", "Code", "Year"}
+		for _, source := range mixColumns {
+			header = append(header, MixSources[source])
+		}
+		for _, entry := range entries {
+			row := []interface{}{entry.Country, entry.CountryCode, entry.Year}
+			for _, source := range mixColumns {
+				if share, ok := entry.Sources[source]; ok {
+					row = append(row, share)
+				} else {
+					row = append(row, nil)
+				}
+			}
+			rows = append(rows, row)
+		}
+	case structures.Comparison:
+		//a row per country and year, like the history of each country after another
+		header = dataHeader(entries.Metric)
+		if entries.Baseline != "" {
+			header = append(header, "difference")
+		}
+		for _, country := range entries.Countries {
+			for i, year := range entries.Years {
+				row := []interface{}{country.Country, country.CountryCode, year, valueCell(country.Values[i])}
+				if entries.Baseline != "" {
+					row = append(row, valueCell(country.Difference[i]))
+				}
+				rows = append(rows, row)
+			}
+		}
+	case structures.Trend:
+		header = []string{"Entity", "Code", "metric", "years", "firstYear", "first", "lastYear", "last", "minYear", "min",
+			"maxYear", "max", "absoluteChange", "relativeChange", "cagr", "slope", "intercept", "rSquared", "volatility"}
+		rows = append(rows, []interface{}{entries.Country, entries.CountryCode, entries.Metric, entries.Years,
+			entries.First.Year, entries.First.Value, entries.Last.Year, entries.Last.Value, entries.Min.Year,
+			entries.Min.Value, entries.Max.Year, entries.Max.Value, entries.AbsoluteChange,
+			valueCell(entries.RelativeChange), valueCell(entries.CAGR), entries.Slope, entries.Intercept,
+			valueCell(entries.RSquared), entries.Volatility})
+	default:
+		return nil, nil, false
+	}
+	return header, rows, true
+}
+
+var stalenessHeader = []string{"latestYear", "yearsBehind", "stale"}
+
+// the sources of the energy mix in the order of their columns
+var mixColumns = []string{"hydro", "wind", "solar", "biofuel", "other_renewables", "nuclear", "fossil"}
+
+// the columns of energyData.csv, with the metric as the last one. An empty list has no metric, so it is "value"
+func dataHeader(metric string) []string {
+	if metric == structures.DEFAULTMETRIC {
+		metric = structures.RENEWABLESHEADER
+	}
+	return []string{"Entity", "Code", "Year", metric}
+}
+
+func dataRow(entry structures.DataEntry) []interface{} {
+	return []interface{}{entry.Country, entry.CountryCode, entry.Year, entry.Percentage}
+}
+
+func metricRow(entry structures.MetricEntry) []interface{} {
+	return []interface{}{entry.Country, entry.CountryCode, entry.Year, entry.Value}
+}
+
+func stalenessRow(staleness structures.Staleness) []interface{} {
+	return []interface{}{staleness.LatestYear, staleness.YearsBehind, staleness.Stale}
+}
+
+func distanceCell(distance *int) interface{} {
+	if distance == nil {
+		return nil
+	}
+	return *distance
+}
+
+func valueCell(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
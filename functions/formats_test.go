@@ -0,0 +1,132 @@
+package functions
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/structures"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	testCases := []struct {
+		format, accept string
+		expected       string
+		status         int
+	}{
+		{format: "", accept: "", expected: structures.FORMAT_JSON},
+		{format: "CSV", accept: "application/json", expected: structures.FORMAT_CSV},
+		{format: "", accept: "text/csv", expected: structures.FORMAT_CSV},
+		{format: "", accept: "application/x-ndjson", expected: structures.FORMAT_NDJSON},
+		{format: "", accept: "text/csv;q=0.5, " + structures.XLSX_CONTENT_TYPE, expected: structures.FORMAT_XLSX},
+		{format: "", accept: "text/html,application/xhtml+xml,*/*;q=0.8", expected: structures.FORMAT_JSON},
+		{format: "", accept: "text/csv;q=0, application/json", expected: structures.FORMAT_JSON},
+		{format: "pdf", accept: "", status: http.StatusBadRequest},
+		{format: "", accept: "image/png", status: http.StatusNotAcceptable},
+	}
+	for _, tc := range testCases {
+		format, err := NegotiateFormat(tc.format, tc.accept)
+		if tc.status != 0 {
+			apiErr, ok := err.(*structures.APIError)
+			assert.True(t, ok, tc.accept)
+			assert.Equal(t, tc.status, apiErr.Status, tc.accept)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, format, tc.accept)
+	}
+}
+
+// the csv of data entries has the columns of the data file, so it can be read as one
+func TestWriteCSV(t *testing.T) {
+	entries := []structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.55836},
+		{Country: "Bosnia, Herzegovina", CountryCode: "BIH", Year: 2021, Percentage: 30.2},
+	}
+	header, rows, ok := Table(entries)
+	assert.True(t, ok)
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteCSV(&buffer, header, rows))
+	assert.True(t, strings.HasPrefix(buffer.String(), "Entity,Code,Year,"+structures.RENEWABLESHEADER+"\n"))
+
+	path := filepath.Join(t.TempDir(), "energyData.csv")
+	assert.NoError(t, ioutil.WriteFile(path, buffer.Bytes(), 0644))
+	table, err := ReadTable(path)
+	assert.NoError(t, err)
+	assert.Equal(t, entries, dataset.FromTable(table).All())
+}
+
+func TestTable(t *testing.T) {
+	distance := 1
+	header, rows, ok := Table([]structures.CurrentEntry{{
+		DataEntry: structures.DataEntry{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9},
+		Staleness: structures.Staleness{LatestYear: 2022, YearsBehind: 1, Stale: true},
+		Distance:  &distance,
+	}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Entity", "Code", "Year", structures.RENEWABLESHEADER, "latestYear", "yearsBehind", "stale", "distance"}, header)
+	assert.Equal(t, [][]interface{}{{"Sweden", "SWE", 2021, 50.9, 2022, 1, true, 1}}, rows)
+
+	//another metric is named by its own name
+	header, _, ok = Table([]structures.MetricEntry{{Country: "Sweden", CountryCode: "SWE", Year: 2021, Metric: "wind_share_energy", Value: 9.1}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Entity", "Code", "Year", "wind_share_energy"}, header)
+
+	//the mix has a column per source, and a comparison a row per country and year
+	header, rows, ok = Table([]structures.Mix{{Country: "Norway", CountryCode: "NOR", Year: 2021,
+		Sources: map[string]float64{"hydro": 64.2, "fossil": 28.5}}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Entity", "Code", "Year", "hydro_share_energy", "wind_share_energy", "solar_share_energy",
+		"biofuel_share_energy", "other_renewables_share_energy", "nuclear_share_energy", "fossil_share_energy"}, header)
+	assert.Equal(t, [][]interface{}{{"Norway", "NOR", 2021, 64.2, nil, nil, nil, nil, nil, 28.5}}, rows)
+
+	value, difference := 71.6, 20.7
+	header, rows, ok = Table(structures.Comparison{Metric: structures.DEFAULTMETRIC, Years: []int{2020, 2021}, Baseline: "SWE",
+		Countries: []structures.ComparedSeries{{Country: "Norway", CountryCode: "NOR", Values: []*float64{nil, &value},
+			Difference: []*float64{nil, &difference}}}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Entity", "Code", "Year", structures.RENEWABLESHEADER, "difference"}, header)
+	assert.Equal(t, [][]interface{}{{"Norway", "NOR", 2020, nil, nil}, {"Norway", "NOR", 2021, 71.6, 20.7}}, rows)
+
+	_, rows, ok = Table(structures.Trend{Country: "Norway", CountryCode: "NOR"})
+	assert.True(t, ok)
+	assert.Len(t, rows, 1)
+
+	_, _, ok = Table(structures.Info{})
+	assert.False(t, ok)
+}
+
+func TestPrintFormat(t *testing.T) {
+	entries := []structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9},
+	}
+
+	rr := httptest.NewRecorder()
+	PrintFormat(rr, structures.NeighbourResponse{Entries: entries, Warnings: []string{"Error reading neighbour Finland"}},
+		structures.FORMAT_NDJSON)
+	assert.Equal(t, structures.NDJSON_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+	assert.Equal(t, `199 - "Error reading neighbour Finland"`, rr.Header().Get("Warning"))
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	entry := structures.DataEntry{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, entries[1], entry)
+
+	rr = httptest.NewRecorder()
+	PrintFormat(rr, entries, structures.FORMAT_CSV)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Entity,Code,Year,"+structures.RENEWABLESHEADER+"\nNorway,NOR,2021,71.6\nSweden,SWE,2021,50.9\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	PrintFormat(rr, structures.Info{}, structures.FORMAT_CSV)
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+}
@@ -2,224 +2,184 @@ package functions
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"net/url"
 	"context"
-	"strings"
+	"strconv"
 
-	"groupXX/firebase"
+	"groupXX/cache"
+	"groupXX/countries"
 	"groupXX/structures"
 )
 
-//used to check if we should use country name or country code
-func IsCountryCode(countryInput string) bool {
-	if len(countryInput) == 3 {
-		return true
-	} else {
-		return false
-	}
+//functions to retrieve the specified country info
+func ReadCountryInfo(searchInput string, current bool, begin *int, end *int) ([]structures.DataEntry, error) {
+	return ReadMetricInfo(structures.DEFAULTMETRIC, searchInput, current, begin, end)
 }
 
-//functions to retrieve the specified country info
-func ReadCountryInfo(w http.ResponseWriter, searchInput string, current bool, begin *int, end *int) ([]structures.DataEntry, error) {
-	//if not specified search input just read the file completly because for no specified country 
-	//this becomes more effecient, if current only write for current year, else all
+//retrieves the specified country info for any metric in the data, with the values of the metric as percentages.
+//A country which isn't in the data returns no entries and no error, so the caller can answer with suggestions
+func ReadMetricInfo(metric string, searchInput string, current bool, begin *int, end *int) ([]structures.DataEntry, error) {
+	//the data is read once, so a reload during the request doesn't mix old and new data
+	loaded := CurrentData()
+	if loaded == nil {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No data loaded")
+	}
+	loaded, ok := loaded.Metric(metric)
+	if !ok {
+		return nil, NewError(http.StatusBadRequest, structures.ERR_UNKNOWN_METRIC, "Unknown metric "+metric)
+	}
+
+	//if not specified search input every country is returned, from the index instead of reading the file again
 	if searchInput == ""{
 		if current == true{
-			//a map presaved with only current countries
-			return OnlyCurrent, nil
+			return loaded.Countries(), nil
 		} else{
-			allCountries, err := RetrieveAll(structures.FILEPATH, false)
-			if err != nil{
-				log.Printf("Error retrieving countries from file: %v", err)
-				http.Error(w, "Error retrieving countries from file: "+err.Error(), http.StatusInternalServerError)
-			}
-			return allCountries, nil
+			return loaded.Between(begin, end), nil
 		}
 	}
 
-	//generate cache key
-	cacheKey := fmt.Sprintf("%s_%v_%v_%v", searchInput, current, begin, end)
-	
+	//generate cache key, with the version of the data so searches cached before a reload aren't used
+	cacheKey := loaded.Version() + "_" + loaded.MetricName() + "_" + CacheKey(searchInput, current, begin, end)
+
 	ctx := context.Background()
 
 	//Try getting data from cache
-	cachedData, err := firebase.GetCachedData(ctx, cacheKey)
-	if err != nil {
-		log.Printf("Error getting cached data: %v", err)
-	} else if cachedData != nil {
+	cachedData := cache.Searches.Get(ctx, cacheKey)
+	if cachedData != nil {
 		return cachedData, nil
 	}
 
-	//call ExtractByMap to get matching countries
-	matchingCountries, err := ExtractByMap(searchInput)
-	if err != nil {
-		return nil, err
-	} else if matchingCountries == nil{
+	if !loaded.Has(searchInput) {
 		return nil, nil
 	}
 
-	//filter the matching countries based on the additional criteria
+	//the current year is the latest year the country has data for, which isn't the same for every country
 	var data []structures.DataEntry
-	//goes trough all matching countries
-	for _, entry := range matchingCountries {
-		//if the user want current year which as of our data is in 2021
-		if current {
-			if entry.Year != 2021 {
-				continue
-			}
-		}
+	if current {
+		data = loaded.Current(searchInput)
+	} else {
+		//the years are sorted in the index, so the range is found by binary search instead of checking every year
+		data = loaded.Range(searchInput, begin, end)
+	}
 
-		//specifications for begin and end, where it can have both or either
-		if begin != nil && end != nil {
-			if entry.Year < *begin || entry.Year > *end {
-				continue
-			}
-		} else if begin != nil {
-			if entry.Year < *begin {
-				continue
-			}
-		} else if end != nil {
-			if entry.Year > *end {
-				continue
-			}
-		}
+	//the data wasn't found in cache, so cache the data
+	cache.Searches.Set(ctx, cacheKey, data)
+	return data, nil
+}
 
-		//appends the data that have "passed" all checks and haven't been "continue'd"
-		data = append(data, entry)
+//adds how many years each entry is behind the latest year in the data, entries from an earlier year are stale
+func MarkStaleness(data []structures.DataEntry, latestYear int) []structures.CurrentEntry {
+	marked := make([]structures.CurrentEntry, len(data))
+	for i, entry := range data {
+		marked[i] = structures.CurrentEntry{DataEntry: entry, Staleness: staleness(entry.Year, latestYear)}
 	}
+	return marked
+}
 
-	//if the data wasn't found in cache, cache the data
-	if cachedData == nil{
-		err := firebase.SetCachedData(ctx, cacheKey, data)
-		if err != nil {
-			log.Printf("Error setting cached data: %v", err)
-		}
+//same as MarkStaleness for another metric than the default
+func MarkMetricStaleness(data []structures.DataEntry, metric string, latestYear int) []structures.CurrentMetricEntry {
+	marked := make([]structures.CurrentMetricEntry, len(data))
+	for i, entry := range ToMetricEntries(data, metric) {
+		marked[i] = structures.CurrentMetricEntry{MetricEntry: entry, Staleness: staleness(entry.Year, latestYear)}
 	}
-	return data, nil
+	return marked
 }
 
-//function to retrieve a countries neighbour
-func RetrieveNeighbours(w http.ResponseWriter, searchCountry string) ([]string, error) {
-	//sets the contet type to JSON format
-	w.Header().Add("content-type", "application/json")
-	//string list which stroes country names
-	var borderNames []string
+func staleness(year int, latestYear int) structures.Staleness {
+	return structures.Staleness{LatestYear: latestYear, YearsBehind: latestYear - year, Stale: year < latestYear}
+}
 
-	//get's main countries
-	countryBorders, err := GetCountryData(w, searchCountry,  structures.COUNTRYSEARCH)
-	if err != nil {
-		//if the country wasn't found don't report error because a country isn't obligated to have neighbours
-		//so just return empty list
-		if err.Error() == "Country not found" {
-			return []string{}, nil
-		}
-		log.Printf("Error getting country API: %v", err)
-		http.Error(w, "Error getting country API: "+err.Error(), http.StatusInternalServerError)
-		return nil, err
-	}
-
-	//loops trough each country retrieved from the restcountries api
-	for _, country := range countryBorders {
-		//loops trough that counties border countries
-		for _, borderCountry := range country.Borders {
-			//gets the data for that border country
-			borderCountries, err := GetCountryData(w, borderCountry, structures.COUNTRYSEARCH)
-			if err != nil {
-				if err.Error() == "Country not found" {
-					continue
-				}
-				log.Printf("Error getting country API: %v", err)
-				http.Error(w, "Error getting country API: "+err.Error(), http.StatusInternalServerError)
-				return nil, err
-			}
-			//appends the found names of the border countries to the list of border names
-			borderNames = append(borderNames, borderCountries[0].Name.Common)
+//names the metric of entries read with ReadMetricInfo, where the value is kept as the percentage
+func ToMetricEntries(data []structures.DataEntry, metric string) []structures.MetricEntry {
+	entries := make([]structures.MetricEntry, len(data))
+	for i, entry := range data {
+		entries[i] = structures.MetricEntry{
+			Country:     entry.Country,
+			CountryCode: entry.CountryCode,
+			Year:        entry.Year,
+			Metric:      metric,
+			Value:       entry.Percentage,
 		}
 	}
+	return entries
+}
+
+//key of a search in the cache, with the values of begin and end instead of their addresses
+func CacheKey(searchInput string, current bool, begin *int, end *int) string {
+	beginStr, endStr := "<nil>", "<nil>"
+	if begin != nil {
+		beginStr = strconv.Itoa(*begin)
+	}
+	if end != nil {
+		endStr = strconv.Itoa(*end)
+	}
+	return fmt.Sprintf("%s_%v_%s_%s", searchInput, current, beginStr, endStr)
+}
+
+//function to retrieve a countries neighbours, from the local country data so the countries API isn't called
+func RetrieveNeighbours(searchCountry string) ([]string, error) {
+	if countries.Default() == nil {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No country data loaded")
+	}
+
+	//if the country wasn't found don't report error because a country isn't obligated to have neighbours
+	//so just return empty list
+	neighbours, ok := countries.Default().Neighbours(searchCountry)
+	if !ok {
+		return []string{}, nil
+	}
 
+	//string list which stores the names of the border countries
+	borderNames := make([]string, 0, len(neighbours))
+	for _, neighbour := range neighbours {
+		borderNames = append(borderNames, neighbour.Name.Common)
+	}
 	return borderNames, nil
 }
 
-//TEST DENNE TA URL SOM PARAMETER
-//function to get country data, returns list of the country struct
-func GetCountryData(w http.ResponseWriter, country string, path string) ([]structures.Country, error) {
-    var responseCountryBody []byte
-    var err error
-
-    if path == structures.TESTCOUNTRYFILE {
-        // Read the test country file
-        responseCountryBody, err = ioutil.ReadFile(path)
-        if err != nil {
-            log.Printf("Error reading test country file: %v", err)
-            http.Error(w, "Error reading test country file: "+err.Error(), http.StatusInternalServerError)
-            return nil, err
-        }
-    } else {
-        var responseCountry *http.Response
-		//
-		if IsCountryCode(path){
-        	responseCountry, err = http.Get("http://129.241.150.113:8080/v3.1/alpha/" + url.PathEscape(country))
-		} else {
-			responseCountry, err = http.Get("http://129.241.150.113:8080/v3.1/name/" + url.PathEscape(country))
-		}
+//function to get country data from the client, by its alpha-3 code if the local countries know the input as any
+//code, name or alias and else by name, returns list of the country struct. The lookup gives up when the context is
+//done. A country which isn't found returns an empty list and structures.ErrCountryNotFound, other errors of the
+//client are returned as an unavailable API when the breaker is open and as an upstream error otherwise
+func GetCountryData(ctx context.Context, client countries.Client, country string) ([]structures.Country, error) {
+	if known, ok := countries.Resolve(country); ok {
+		return GetCountryByCode(ctx, client, known.Alpha3)
+	}
+	found, err := client.ByName(ctx, country)
+	return countryLookup(found, err, country)
+}
+
+//function to get country data from the client by a code the caller already knows is one, like the iso code of
+//an entry in the data, with the errors of GetCountryData
+func GetCountryByCode(ctx context.Context, client countries.Client, code string) ([]structures.Country, error) {
+	found, err := client.ByCode(ctx, code)
+	return countryLookup(found, err, code)
+}
 
-        if err != nil {
-            log.Printf("Error getting country API: %v for %s", err, country)
-            http.Error(w, "Error getting country API: "+err.Error(), http.StatusInternalServerError)
-            return nil, err
-        }
-
-        defer responseCountry.Body.Close()
-
-        if responseCountry.StatusCode != http.StatusOK {
-            if responseCountry.StatusCode == http.StatusNotFound {
-                return []structures.Country{}, fmt.Errorf("Country not found")
-            }
-            err := fmt.Errorf("Country API returned a non-200 status code: %v", responseCountry.StatusCode)
-            log.Printf("Error getting country API: %v", err)
-            http.Error(w, err.Error(), http.StatusInternalServerError)
-            return nil, err
-        }
-
-        responseCountryBody, err = ioutil.ReadAll(responseCountry.Body)
-        if err != nil {
-            log.Printf("Error reading country API response: %v", err)
-            http.Error(w, "Error reading country API response: "+err.Error(), http.StatusInternalServerError)
-            return nil, err
-        }
-    }
-
-    var countries []structures.Country
-    err = json.Unmarshal(responseCountryBody, &countries)
-    if err != nil {
-        log.Printf("Error decoding country API response: %v", err)
-        http.Error(w, "Error decoding country API response: "+err.Error(), http.StatusInternalServerError)
-        return nil, err
-    }
-
-	if path == structures.TESTCOUNTRYFILE {
-        matchingCountries := make([]structures.Country, 0)
-        for _, c := range countries {
-            if strings.EqualFold(c.Name.Common, country) {
-                matchingCountries = append(matchingCountries, c)
-            }
-        }
-        return matchingCountries, nil
-    }
-
-    return countries, nil
+//the answer of a lookup as it is given to the user
+func countryLookup(found []structures.Country, err error, country string) ([]structures.Country, error) {
+	if errors.Is(err, structures.ErrCountryNotFound) {
+		return []structures.Country{}, err
+	}
+	if errors.Is(err, structures.ErrBreakerOpen) {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, err.Error())
+	}
+	if err != nil {
+		log.Printf("Error getting country API: %v for %s", err, country)
+		return nil, NewError(http.StatusBadGateway, structures.ERR_UPSTREAM, "Error getting country API: "+err.Error())
+	}
+	return found, nil
 }
 
-// function which outputs data in a desired format
+// function which outputs data in a desired format, data which can't be encoded is written as an internal error
 func PrintData(w http.ResponseWriter, data interface{}) {
 	encoded, err := json.Marshal(data)
 	if err != nil {
-		log.Printf("Error encoding API response: %v", err)
-		http.Error(w, "Error encoding API response: "+err.Error(), http.StatusInternalServerError)
+		WriteError(w, nil, fmt.Errorf("Error encoding API response: %v", err))
+		return
 	}
 	fmt.Fprintln(w, string(encoded))
 }
@@ -1,51 +1,37 @@
 package functions
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
-	"time"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"sort"
 
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries"
+	"groupXX/countries/countriestest"
+	"groupXX/dataset"
 	"groupXX/structures"
 )
 
-func TestIsCountryCode(t *testing.T) {
-	rand.Seed(time.Now().UnixNano())
-
-	//generates random strings of different lengths
-	for i := 0; i < 100; i++ {
-		length := rand.Intn(6) // Generate a random length between 0 and 5
-		randomStr := randomString(length)
-
-		//checks if the IsCountryCode function returns the correct output
-		result := IsCountryCode(randomStr)
-		if length == 3 && !result {
-			t.Errorf("Expected true for %s, but got false", randomStr)
-		} else if length != 3 && result {
-			t.Errorf("Expected false for %s, but got true", randomStr)
-		}
-	}
-}
-
-// function to create random string
-func randomString(n int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	str := make([]byte, n)
-	for i := range str {
-		str[i] = letters[rand.Intn(len(letters))]
+func TestRetrieveNeighbours(t *testing.T) {
+	//the neighbours are read from the local country data
+	resolver, err := countries.Load("../structures/countries.json")
+	if err != nil {
+		t.Fatalf("Loading countries failed: %v", err)
 	}
-	return string(str)
-}
+	defaultResolver := countries.Default()
+	defer func() { countries.SetDefault(defaultResolver) }()
+	countries.SetDefault(resolver)
 
-func TestRetrieveNeighbours(t *testing.T) {
 	testCases := []struct {
 		countryName        string
 		expectedNeighbours []string
@@ -63,9 +49,7 @@ func TestRetrieveNeighbours(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.countryName, func(t *testing.T) {
-			rr := httptest.NewRecorder()
-
-			neighbours, err := RetrieveNeighbours(rr, tc.countryName)
+			neighbours, err := RetrieveNeighbours(tc.countryName)
 			if err != nil {
 				t.Errorf("RetrieveNeighbours failed: %v", err)
 				return
@@ -92,6 +76,42 @@ func TestRetrieveNeighbours(t *testing.T) {
 }
 
 
+func TestGetCountryDataFromServer(t *testing.T) {
+	server := countriestest.NewServer([]structures.Country{
+		{Borders: []string{"IND"}, CountryCode: "LK", Alpha3: "LKA", Name: structures.CountryName{Common: "Sri Lanka"}},
+	})
+	defer server.Close()
+	client := countries.NewHTTPClient(server.BaseURL)
+
+	result, err := GetCountryData(context.Background(), client, "Sri Lanka")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"IND"}, result[0].Borders)
+
+	//a country the local countries know is looked up by its alpha-3 code, whatever it was given as
+	defaultResolver := countries.Default()
+	defer countries.SetDefault(defaultResolver)
+	countries.SetDefault(countries.New([]structures.Country{
+		{CountryCode: "LK", Alpha3: "LKA", Name: structures.CountryName{Common: "Sri Lanka"}},
+	}))
+	result, err = GetCountryData(context.Background(), client, "lk")
+	assert.NoError(t, err)
+	assert.Equal(t, "Sri Lanka", result[0].Name.Common)
+	assert.Equal(t, "/v3.1/alpha/LKA", server.Requests()[1].URL.Path)
+
+	result, err = GetCountryData(context.Background(), client, "Atlantis")
+	assert.Equal(t, structures.ErrCountryNotFound, err)
+	assert.Empty(t, result)
+
+	//an API which fails is an upstream error for the user
+	server.Fail(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	client.Backoff = 0
+	_, err = GetCountryData(context.Background(), client, "Croatia")
+	apiErr := &structures.APIError{}
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.Status)
+	assert.Equal(t, structures.ERR_UPSTREAM, apiErr.Code)
+}
+
 func TestGetCountryDataFromFile(t *testing.T) {
 	// List of test cases with random country names and sample data
 	testCases := []struct {
@@ -104,9 +124,7 @@ func TestGetCountryDataFromFile(t *testing.T) {
 			sampleData: structures.Country{
 				Borders:     []string{"AUT", "HRV", "ITA", "HUN"},
 				CountryCode: "SI",
-				Name: struct {
-					Common string `json:"common"`
-				}{
+				Name: structures.CountryName{
 					Common: "Slovenia",
 				},
 			},
@@ -115,11 +133,8 @@ func TestGetCountryDataFromFile(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.countryName, func(t *testing.T) {
-			// Create a ResponseRecorder to capture the output of the GetCountryData function
-			rr := httptest.NewRecorder()
-
 			// Call GetCountryData function with the test country file and the test case's country name
-			result, err := GetCountryData(rr, tc.countryName, structures.TESTCOUNTRYFILE)
+			result, err := GetCountryData(context.Background(), countries.FileClient{Path: structures.TESTCOUNTRYFILE}, tc.countryName)
 			if err != nil {
 				t.Errorf("GetCountryData failed: %v", err)
 				return
@@ -181,7 +196,7 @@ func TestPrintData(t *testing.T) {
 			name:     "TestPrintData_InvalidData",
 			data:     func() {},
 			hasError: true,
-			expected: "Error encoding API response: json: unsupported type: func()",
+			expected: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal server error","code":"internal_error"}`,
 		},
 	}
 
@@ -254,3 +269,30 @@ func StubbingCountryData() {
 
 	fmt.Println("Successfully saved countriesData.json")
 }
+
+func TestReadCountryInfoCurrent(t *testing.T) {
+	defaultData := CurrentData()
+	defer SetData(defaultData)
+	SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2022, Percentage: 72.1},
+		{Country: "Norway", CountryCode: "NOR", Year: 2023, Percentage: 73.4},
+		{Country: "Denmark", CountryCode: "DNK", Year: 2021, Percentage: 35.2},
+	}))
+
+	//the current year is the latest year of each country
+	data, err := ReadCountryInfo("Norway", true, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2023, Percentage: 73.4}}, data)
+
+	data, err = ReadCountryInfo("dnk", true, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2021, data[0].Year)
+
+	data, err = ReadCountryInfo("", true, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, data, 2)
+
+	marked := MarkStaleness(data, CurrentData().LatestYear())
+	assert.Equal(t, structures.CurrentEntry{DataEntry: data[0], Staleness: structures.Staleness{LatestYear: 2023, YearsBehind: 0, Stale: false}}, marked[0])
+	assert.Equal(t, structures.CurrentEntry{DataEntry: data[1], Staleness: structures.Staleness{LatestYear: 2023, YearsBehind: 2, Stale: true}}, marked[1])
+}
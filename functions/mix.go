@@ -0,0 +1,98 @@
+package functions
+
+import (
+	"net/http"
+	"sort"
+
+	"groupXX/dataset"
+	"groupXX/structures"
+)
+
+// the sources of the energy mix and the metric each of them is read from
+var MixSources = map[string]string{
+	"hydro":            "hydro_share_energy",
+	"wind":             "wind_share_energy",
+	"solar":            "solar_share_energy",
+	"biofuel":          "biofuel_share_energy",
+	"other_renewables": "other_renewables_share_energy",
+	"nuclear":          "nuclear_share_energy",
+	"fossil":           "fossil_share_energy",
+}
+
+// returns the share of each source for every year from begin to end, both included. If neither is given
+// only the latest year the country has any share for is returned. Nil if the country isn't found, and an empty
+// list if it has no shares for the years. Data without any of the sources, like the bundled renewables file, is
+// unavailable
+func EnergyMix(country string, begin *int, end *int) ([]structures.Mix, error) {
+	loaded := CurrentData()
+	if loaded == nil {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No data loaded")
+	}
+	//the sources which are loaded
+	sources := make(map[string]*dataset.Dataset)
+	for _, source := range mixColumns {
+		if data, ok := loaded.Metric(MixSources[source]); ok {
+			sources[source] = data
+		}
+	}
+	if len(sources) == 0 {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE,
+			"The loaded data has none of the *_share_energy metrics of the energy mix, it needs the full Our World in Data energy file")
+	}
+
+	//finds the country the same way as the other endpoints in the sources, so a country with shares but without
+	//a renewables percentage is found too, and then uses its code so every source is the same country
+	var identity structures.DataEntry
+	for _, source := range mixColumns {
+		if data, ok := sources[source]; ok {
+			if matching := data.Country(country); len(matching) > 0 {
+				identity = matching[0]
+				break
+			}
+		}
+	}
+	if identity.Country == "" {
+		return nil, nil
+	}
+	key := identity.CountryCode
+	if key == "" {
+		key = identity.Country
+	}
+
+	if begin == nil && end == nil {
+		latestYear := 0
+		for _, data := range sources {
+			if current := data.Current(key); len(current) > 0 && current[0].Year > latestYear {
+				latestYear = current[0].Year
+			}
+		}
+		if latestYear == 0 {
+			return []structures.Mix{}, nil
+		}
+		begin, end = &latestYear, &latestYear
+	}
+
+	byYear := make(map[int]*structures.Mix)
+	for source, data := range sources {
+		for _, entry := range data.Range(key, begin, end) {
+			mix, ok := byYear[entry.Year]
+			if !ok {
+				mix = &structures.Mix{
+					Country:     identity.Country,
+					CountryCode: identity.CountryCode,
+					Year:        entry.Year,
+					Sources:     make(map[string]float64),
+				}
+				byYear[entry.Year] = mix
+			}
+			mix.Sources[source] = entry.Percentage
+		}
+	}
+
+	mixes := []structures.Mix{}
+	for _, mix := range byYear {
+		mixes = append(mixes, *mix)
+	}
+	sort.Slice(mixes, func(i, j int) bool { return mixes[i].Year < mixes[j].Year })
+	return mixes, nil
+}
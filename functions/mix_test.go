@@ -0,0 +1,70 @@
+package functions
+
+import (
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/structures"
+)
+
+func TestEnergyMix(t *testing.T) {
+	defaultData := CurrentData()
+	defer SetData(defaultData)
+	nan := math.NaN()
+	SetData(dataset.FromTable(&dataset.Table{
+		Rows: []structures.DataEntry{
+			{Country: "Norway", CountryCode: "NOR", Year: 2020},
+			{Country: "Norway", CountryCode: "NOR", Year: 2021},
+			{Country: "Norway", CountryCode: "NOR", Year: 2022},
+			{Country: "Sweden", CountryCode: "SWE", Year: 2021},
+		},
+		Columns: map[string][]float64{
+			structures.DEFAULTMETRIC: {70.9, 71.5, 72.0, nan},
+			"hydro_share_energy":     {65.1, 64.2, nan, 28.1},
+			"wind_share_energy":      {4.9, 6.1, nan, 10.6},
+			"fossil_share_energy":    {29.1, 28.5, nan, 18.7},
+		},
+	}))
+
+	//the latest year with any share
+	mixes, err := EnergyMix("Norway", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.Mix{{Country: "Norway", CountryCode: "NOR", Year: 2021,
+		Sources: map[string]float64{"hydro": 64.2, "wind": 6.1, "fossil": 28.5}}}, mixes)
+
+	begin, end := 2019, 2022
+	mixes, err = EnergyMix("nor", &begin, &end)
+	assert.NoError(t, err)
+	assert.Len(t, mixes, 2)
+	assert.Equal(t, 2020, mixes[0].Year)
+	assert.Equal(t, 65.1, mixes[0].Sources["hydro"])
+
+	//a known country without shares for the years is an empty list, not a country which isn't found
+	begin, end = 2022, 2022
+	mixes, err = EnergyMix("nor", &begin, &end)
+	assert.NoError(t, err)
+	assert.NotNil(t, mixes)
+	assert.Empty(t, mixes)
+
+	//a country with shares but without a renewables percentage is found in the sources
+	mixes, err = EnergyMix("sweden", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.Mix{{Country: "Sweden", CountryCode: "SWE", Year: 2021,
+		Sources: map[string]float64{"hydro": 28.1, "wind": 10.6, "fossil": 18.7}}}, mixes)
+
+	mixes, err = EnergyMix("Atlantis", nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, mixes)
+
+	//data like the bundled renewables file has none of the sources
+	SetData(dataset.New([]structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.5}}))
+	_, err = EnergyMix("Norway", nil, nil)
+	apiErr, ok := err.(*structures.APIError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.Status)
+	assert.Equal(t, structures.ERR_UNAVAILABLE, apiErr.Code)
+}
@@ -0,0 +1,154 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"groupXX/countries"
+	"groupXX/structures"
+)
+
+// returns every country within depth land borders of the country, given as its iso code, not the country itself,
+// ordered by the distance and then by name. A country which isn't in the local countries is looked up by its code
+// with the default client, which is behind the circuit breaker, and a country which isn't found there either has
+// no neighbours. If the lookup fails or isn't done before the context is, the country has no neighbours either
+// and the failure is returned as a warning
+func NeighboursWithin(ctx context.Context, country string, depth int) ([]structures.Neighbour, []string, error) {
+	if countries.Default() == nil {
+		return nil, nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No country data loaded")
+	}
+	warnings := make([]string, 0)
+	start, ok := countries.Default().Resolve(country)
+	if !ok {
+		found, err := GetCountryByCode(ctx, countries.DefaultClient, country)
+		if errors.Is(err, structures.ErrCountryNotFound) || (err == nil && len(found) == 0) {
+			return []structures.Neighbour{}, warnings, nil
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Error looking up the borders of %s: %v", country, err))
+			return []structures.Neighbour{}, warnings, nil
+		}
+		start = found[0]
+	}
+
+	//breadth first through the borders, so each country is found at its shortest distance
+	visited := map[string]bool{start.Alpha3: true}
+	frontier := []structures.Country{start}
+	neighbours := make([]structures.Neighbour, 0)
+	for distance := 1; distance <= depth && len(frontier) > 0; distance++ {
+		var next []structures.Country
+		for _, current := range frontier {
+			borders := bordersOf(current)
+			for _, border := range borders {
+				if visited[border.Alpha3] {
+					continue
+				}
+				visited[border.Alpha3] = true
+				next = append(next, border)
+				neighbours = append(neighbours, structures.Neighbour{
+					Country:  border.Name.Common,
+					Alpha3:   border.Alpha3,
+					Distance: distance,
+				})
+			}
+		}
+		frontier = next
+	}
+
+	sort.SliceStable(neighbours, func(i, j int) bool {
+		if neighbours[i].Distance != neighbours[j].Distance {
+			return neighbours[i].Distance < neighbours[j].Distance
+		}
+		return neighbours[i].Country < neighbours[j].Country
+	})
+	return neighbours, warnings, nil
+}
+
+// the countries bordering the country, a country from the API has its borders looked up in the local countries
+func bordersOf(country structures.Country) []structures.Country {
+	if borders, ok := countries.Default().Neighbours(country.Alpha3); ok {
+		return borders
+	}
+	borders := make([]structures.Country, 0, len(country.Borders))
+	for _, border := range country.Borders {
+		if found, ok := countries.Default().Resolve(border); ok {
+			borders = append(borders, found)
+		}
+	}
+	return borders
+}
+
+// result of reading one neighbour, by its index in the neighbours
+type neighbourResult struct {
+	index int
+	data  []structures.DataEntry
+	err   error
+}
+
+// reads the data of every neighbour with at most NEIGHBOURWORKERS at once. The data is returned in the order of
+// the neighbours, and a neighbour which fails or isn't read before the context is done gets a warning instead
+func ReadNeighbours(ctx context.Context, neighbours []structures.Neighbour,
+	read func(country string) ([]structures.DataEntry, error)) ([][]structures.DataEntry, []string) {
+	data := make([][]structures.DataEntry, len(neighbours))
+	warnings := make([]string, 0)
+	if len(neighbours) == 0 {
+		return data, warnings
+	}
+
+	//buffered so the workers never wait for a reader which has given up
+	results := make(chan neighbourResult, len(neighbours))
+	jobs := make(chan int)
+	workers := structures.NEIGHBOURWORKERS
+	if len(neighbours) < workers {
+		workers = len(neighbours)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for index := range jobs {
+				if err := ctx.Err(); err != nil {
+					results <- neighbourResult{index: index, err: err}
+					continue
+				}
+				entries, err := read(neighbours[index].Alpha3)
+				results <- neighbourResult{index: index, data: entries, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for index := range neighbours {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	errs := make([]error, len(neighbours))
+	received := make([]bool, len(neighbours))
+collect:
+	for count := 0; count < len(neighbours); count++ {
+		select {
+		case result := <-results:
+			data[result.index], errs[result.index], received[result.index] = result.data, result.err, true
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	//the warnings are in the order of the neighbours, so the response doesn't depend on which worker was first
+	for i, neighbour := range neighbours {
+		if !received[i] {
+			errs[i] = ctx.Err()
+		}
+		if errs[i] != nil {
+			data[i] = nil
+			warnings = append(warnings, fmt.Sprintf("Error reading neighbour %s: %v", neighbour.Country, errs[i]))
+		}
+	}
+	return data, warnings
+}
@@ -0,0 +1,143 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries"
+	"groupXX/countries/countriestest"
+	"groupXX/structures"
+)
+
+func TestNeighboursWithin(t *testing.T) {
+	defaultResolver, defaultClient := countries.Default(), countries.DefaultClient
+	defer func() {
+		countries.SetDefault(defaultResolver)
+		countries.DefaultClient = defaultClient
+	}()
+	//Z is only known by the countries API
+	server := countriestest.NewServer([]structures.Country{
+		{Alpha3: "ZZZ", Borders: []string{"AAA"}, Name: structures.CountryName{Common: "Z"}},
+	})
+	defer server.Close()
+	countries.DefaultClient = countries.NewHTTPClient(server.BaseURL)
+	//a line of countries, A-B-C-D, where B also borders E
+	countries.SetDefault(countries.New([]structures.Country{
+		{Alpha3: "AAA", Borders: []string{"BBB"}, Name: structures.CountryName{Common: "A"}},
+		{Alpha3: "BBB", Borders: []string{"AAA", "CCC", "EEE"}, Name: structures.CountryName{Common: "B"}},
+		{Alpha3: "CCC", Borders: []string{"BBB", "DDD"}, Name: structures.CountryName{Common: "C"}},
+		{Alpha3: "DDD", Borders: []string{"CCC"}, Name: structures.CountryName{Common: "D"}},
+		{Alpha3: "EEE", Borders: []string{"BBB"}, Name: structures.CountryName{Common: "E"}},
+	}))
+
+	neighbours, warnings, err := NeighboursWithin(context.Background(), "A", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.Neighbour{{Country: "B", Alpha3: "BBB", Distance: 1}}, neighbours)
+
+	neighbours, warnings, err = NeighboursWithin(context.Background(), "aaa", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.Neighbour{
+		{Country: "B", Alpha3: "BBB", Distance: 1},
+		{Country: "C", Alpha3: "CCC", Distance: 2},
+		{Country: "E", Alpha3: "EEE", Distance: 2},
+		{Country: "D", Alpha3: "DDD", Distance: 3},
+	}, neighbours)
+
+	neighbours, warnings, err = NeighboursWithin(context.Background(), "zzz", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.Neighbour{
+		{Country: "A", Alpha3: "AAA", Distance: 1},
+		{Country: "B", Alpha3: "BBB", Distance: 2},
+	}, neighbours)
+
+	neighbours, warnings, err = NeighboursWithin(context.Background(), "Europe", 2)
+	assert.NoError(t, err)
+	assert.Empty(t, neighbours)
+	assert.Empty(t, warnings)
+
+	//a failed lookup gives no neighbours and a warning instead of failing
+	server.Fail(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	neighbours, warnings, err = NeighboursWithin(context.Background(), "yyy", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, neighbours)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "yyy")
+
+	//and so does a lookup the request has given up on, without waiting for the API
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	requests := len(server.Requests())
+	neighbours, warnings, err = NeighboursWithin(ctx, "xxx", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, neighbours)
+	assert.Len(t, warnings, 1)
+	assert.Len(t, server.Requests(), requests)
+
+	countries.SetDefault(nil)
+	_, _, err = NeighboursWithin(context.Background(), "A", 1)
+	assert.Error(t, err)
+}
+
+func TestReadNeighbours(t *testing.T) {
+	var neighbours []structures.Neighbour
+	for i := 0; i < 20; i++ {
+		code := fmt.Sprintf("C%02d", i)
+		neighbours = append(neighbours, structures.Neighbour{Country: code, Alpha3: code, Distance: 1})
+	}
+
+	//every read waits a little, so the number of reads at the same time can be counted
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	read := func(country string) ([]structures.DataEntry, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if country == "C03" || country == "C11" {
+			return nil, fmt.Errorf("unavailable")
+		}
+		return []structures.DataEntry{{Country: country, CountryCode: country}}, nil
+	}
+
+	data, warnings := ReadNeighbours(context.Background(), neighbours, read)
+	assert.Len(t, data, 20)
+	for i, entries := range data {
+		if i == 3 || i == 11 {
+			assert.Nil(t, entries)
+			continue
+		}
+		assert.Equal(t, neighbours[i].Alpha3, entries[0].CountryCode)
+	}
+	assert.Equal(t, []string{"Error reading neighbour C03: unavailable", "Error reading neighbour C11: unavailable"}, warnings)
+	assert.LessOrEqual(t, maxRunning, structures.NEIGHBOURWORKERS)
+	assert.Greater(t, maxRunning, 1)
+
+	//reads which don't finish before the deadline are left out with a warning
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	data, warnings = ReadNeighbours(ctx, neighbours[:2], func(country string) ([]structures.DataEntry, error) {
+		if country == "C01" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return []structures.DataEntry{{Country: country, CountryCode: country}}, nil
+	})
+	assert.Equal(t, "C00", data[0][0].CountryCode)
+	assert.Nil(t, data[1])
+	assert.Equal(t, []string{"Error reading neighbour C01: context deadline exceeded"}, warnings)
+
+	data, warnings = ReadNeighbours(context.Background(), nil, read)
+	assert.Empty(t, data)
+	assert.Empty(t, warnings)
+}
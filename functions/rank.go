@@ -0,0 +1,104 @@
+package functions
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"groupXX/regions"
+	"groupXX/structures"
+)
+
+// ranks the countries by their percentage for the year, the latest year of the data if it is nil. Only the
+// members are ranked if they are given, by iso code. Countries with the same percentage share the rank, and
+// the rank change is against the ranking of the same countries in the comparison year. A year without any data
+// is not found
+func Rank(year *int, compareYear *int, members []string, ascending bool) ([]structures.RankEntry, error) {
+	loaded := CurrentData()
+	if loaded == nil {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No data loaded")
+	}
+	if year == nil {
+		latestYear := loaded.LatestYear()
+		year = &latestYear
+	}
+
+	var memberSet map[string]bool
+	if members != nil {
+		memberSet = make(map[string]bool)
+		for _, member := range members {
+			memberSet[member] = true
+		}
+	}
+	yearData := loaded.Year(*year)
+	if len(yearData) == 0 {
+		return nil, NewError(http.StatusNotFound, structures.ERR_NOT_FOUND, "No data for the year "+strconv.Itoa(*year)+
+			", the data has the years from "+strconv.Itoa(loaded.EarliestYear())+" to "+strconv.Itoa(loaded.LatestYear()))
+	}
+	ranked := rankYear(yearData, memberSet, ascending)
+	if compareYear == nil {
+		return ranked, nil
+	}
+
+	previous := make(map[string]int)
+	for _, entry := range rankYear(loaded.Year(*compareYear), memberSet, ascending) {
+		previous[entry.CountryCode] = entry.Rank
+	}
+	for i := range ranked {
+		if previousRank, ok := previous[ranked[i].CountryCode]; ok {
+			change := previousRank - ranked[i].Rank
+			ranked[i].PreviousRank, ranked[i].RankChange = &previousRank, &change
+		}
+	}
+	return ranked, nil
+}
+
+// ranks the countries of one year, leaving out regions and countries which aren't members
+func rankYear(data []structures.DataEntry, members map[string]bool, ascending bool) []structures.RankEntry {
+	var countries []structures.DataEntry
+	for _, entry := range data {
+		if regions.IsAggregate(entry) || (members != nil && !members[entry.CountryCode]) {
+			continue
+		}
+		countries = append(countries, entry)
+	}
+
+	//highest first unless ascending, ties by name so the order is stable
+	sort.Slice(countries, func(i, j int) bool {
+		if countries[i].Percentage != countries[j].Percentage {
+			return (countries[i].Percentage > countries[j].Percentage) != ascending
+		}
+		return countries[i].Country < countries[j].Country
+	})
+
+	ranked := make([]structures.RankEntry, len(countries))
+	for i, entry := range countries {
+		rank := i + 1
+		if i > 0 && entry.Percentage == countries[i-1].Percentage {
+			rank = ranked[i-1].Rank
+		}
+		ranked[i] = structures.RankEntry{
+			Rank:        rank,
+			Country:     entry.Country,
+			CountryCode: entry.CountryCode,
+			Year:        entry.Year,
+			Percentage:  entry.Percentage,
+			Percentile:  percentile(countries, entry.Percentage),
+		}
+	}
+	return ranked
+}
+
+// percentage of the countries, other than the one with the value, which have a lower value
+func percentile(countries []structures.DataEntry, value float64) float64 {
+	if len(countries) < 2 {
+		return 100
+	}
+	lower := 0
+	for _, entry := range countries {
+		if entry.Percentage < value {
+			lower++
+		}
+	}
+	return 100 * float64(lower) / float64(len(countries)-1)
+}
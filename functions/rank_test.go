@@ -0,0 +1,59 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/structures"
+)
+
+func TestRank(t *testing.T) {
+	defaultData := CurrentData()
+	defer SetData(defaultData)
+	SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 70},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2020, Percentage: 40},
+		{Country: "Denmark", CountryCode: "DNK", Year: 2020, Percentage: 50},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 72},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 52},
+		{Country: "Denmark", CountryCode: "DNK", Year: 2021, Percentage: 52},
+		{Country: "Finland", CountryCode: "FIN", Year: 2021, Percentage: 35},
+		{Country: "Europe", CountryCode: "", Year: 2021, Percentage: 23},
+	}))
+	year, compareYear := 2021, 2020
+
+	ranked, err := Rank(nil, &compareYear, nil, false)
+	assert.NoError(t, err)
+	assert.Len(t, ranked, 4)
+
+	//Denmark and Sweden share the second place, ordered by name
+	assert.Equal(t, []string{"NOR", "DNK", "SWE", "FIN"}, []string{ranked[0].CountryCode, ranked[1].CountryCode,
+		ranked[2].CountryCode, ranked[3].CountryCode})
+	assert.Equal(t, []int{1, 2, 2, 4}, []int{ranked[0].Rank, ranked[1].Rank, ranked[2].Rank, ranked[3].Rank})
+	assert.Equal(t, 100.0, ranked[0].Percentile)
+	assert.InDelta(t, 100.0/3, ranked[1].Percentile, 1e-9)
+	assert.Equal(t, 0.0, ranked[3].Percentile)
+
+	//Sweden was third in 2020 and has climbed one place, Finland wasn't ranked
+	assert.Equal(t, 3, *ranked[2].PreviousRank)
+	assert.Equal(t, 1, *ranked[2].RankChange)
+	assert.Equal(t, 0, *ranked[0].RankChange)
+	assert.Nil(t, ranked[3].PreviousRank)
+
+	ranked, err = Rank(&year, nil, []string{"SWE", "FIN"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []structures.RankEntry{
+		{Rank: 1, Country: "Finland", CountryCode: "FIN", Year: 2021, Percentage: 35, Percentile: 0},
+		{Rank: 2, Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 52, Percentile: 100},
+	}, ranked)
+
+	//a year without data isn't an empty ranking
+	year = 1900
+	_, err = Rank(&year, nil, nil, false)
+	apiErr, ok := err.(*structures.APIError)
+	assert.True(t, ok)
+	assert.Equal(t, structures.ERR_NOT_FOUND, apiErr.Code)
+	assert.Contains(t, apiErr.Message, "from 2020 to 2021")
+}
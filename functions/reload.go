@@ -0,0 +1,110 @@
+package functions
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"groupXX/cache"
+	"groupXX/dataset"
+	"groupXX/structures"
+	"groupXX/webhooks"
+)
+
+// path the data is loaded from, can be changed before the first Reload
+var DataPath = structures.FILEPATH
+
+// the data in use, swapped as a whole so requests never see a half built index
+var loaded atomic.Value
+
+// only one reload at a time, and the state of the file when it was last loaded
+var reloadMu sync.Mutex
+var loadedModTime time.Time
+var loadedSize int64
+
+func init() {
+	loaded.Store((*dataset.Dataset)(nil))
+}
+
+// returns the data in use, nil until it has been loaded
+func CurrentData() *dataset.Dataset {
+	return loaded.Load().(*dataset.Dataset)
+}
+
+// replaces the data in use and empties the search cache
+func SetData(data *dataset.Dataset) {
+	loaded.Store(data)
+	cache.Searches.Invalidate()
+}
+
+// reads and validates a csv file, without changing the data in use
+func LoadData(path string) (*dataset.Dataset, error) {
+	table, err := ReadTable(path)
+	if err != nil {
+		return nil, err
+	}
+	data := dataset.FromTable(table)
+	//only the default metric is validated, the others have their own units and ranges
+	err = dataset.Validate(data.All())
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// loads DataPath again and swaps it in if it is valid, otherwise the data in use is kept.
+// The threshold webhooks are checked against the new data
+func Reload(ctx context.Context) (*dataset.Dataset, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	//remembered even if the load fails, so the watcher doesn't retry a broken file until it changes again
+	if info, err := os.Stat(DataPath); err == nil {
+		loadedModTime, loadedSize = info.ModTime(), info.Size()
+	}
+
+	data, err := LoadData(DataPath)
+	if err != nil {
+		return nil, err
+	}
+	SetData(data)
+	log.Printf("Loaded %d entries from %s, version %s", data.Len(), DataPath, data.Version())
+
+	if _, err := webhooks.Default.CheckThresholds(ctx, data.All()); err != nil {
+		log.Printf("Error checking threshold webhooks: %v", err)
+	}
+	return data, nil
+}
+
+// reloads the data whenever the file changes, checking every interval until stop is closed
+func WatchData(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !dataChanged() {
+				continue
+			}
+			if _, err := Reload(context.Background()); err != nil {
+				log.Printf("Error reloading changed data, keeping the previous data: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// compares the file with the one which was last loaded
+func dataChanged() bool {
+	info, err := os.Stat(DataPath)
+	if err != nil {
+		return false
+	}
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	return !info.ModTime().Equal(loadedModTime) || info.Size() != loadedSize
+}
@@ -0,0 +1,100 @@
+package functions
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/cache"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+const validData = "Entity,Code,Year,Renewables (% equivalent primary energy)\nNorway,NOR,2020,70.9\nNorway,NOR,2021,71.5\n"
+
+// points DataPath at a file in a temporary directory, restored when the test ends
+func useDataFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "energyData.csv")
+	writeDataFile(t, path, content)
+
+	defaultPath, defaultData := DataPath, CurrentData()
+	t.Cleanup(func() {
+		DataPath = defaultPath
+		SetData(defaultData)
+	})
+	DataPath = path
+	storage.DB = storage.NewMemoryStore()
+	return path
+}
+
+func writeDataFile(t *testing.T, path string, content string) {
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Writing data file failed: %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := useDataFile(t, validData)
+
+	data, err := Reload(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, data.Len())
+	assert.Same(t, data, CurrentData())
+
+	//searches cached before the reload are dropped
+	cache.Searches.Set(context.Background(), "cached", []structures.DataEntry{{Country: "Norway"}})
+	writeDataFile(t, path, validData+"Sweden,SWE,2021,50.9\n")
+	reloaded, err := Reload(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, reloaded.Len())
+	assert.NotEqual(t, data.Version(), reloaded.Version())
+	assert.Nil(t, cache.Searches.Get(context.Background(), "cached"))
+
+	//invalid data is never swapped in
+	for _, content := range []string{
+		"Entity,Code,Year,Renewables (% equivalent primary energy)\n",
+		validData + "Norway,NOR,2021,71.5\n",
+		validData + "Sweden,SWE,2021,150\n",
+	} {
+		writeDataFile(t, path, content)
+		_, err = Reload(context.Background())
+		assert.Error(t, err)
+		assert.Same(t, reloaded, CurrentData())
+	}
+
+	os.Remove(path)
+	_, err = Reload(context.Background())
+	assert.Error(t, err)
+	assert.Same(t, reloaded, CurrentData())
+}
+
+func TestWatchData(t *testing.T) {
+	path := useDataFile(t, validData)
+	_, err := Reload(context.Background())
+	assert.NoError(t, err)
+
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		WatchData(10*time.Millisecond, stop)
+		close(stopped)
+	}()
+	//waits for the watcher so it doesn't read DataPath while it is restored
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+
+	//a different size is noticed even if the modification time is the same
+	writeDataFile(t, path, validData+"Sweden,SWE,2021,50.9\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for CurrentData().Len() != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 3, CurrentData().Len())
+}
@@ -5,144 +5,59 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
-	"unicode"
 
+	"groupXX/dataset"
 	"groupXX/structures"
 )
 
-// BST to store DataEntry structs which are equal to a line of the csv file based on first letter of
-// countryname
-var SpecifiedData *structures.BSTNode
-
-// function initialized at start without having to be called
-func init() {
-	//retrievals all
-	arraysWithData, err := RetrieveAll(structures.FILEPATH, false)
-	if err != nil {
-		fmt.Printf("Error reading CSV file")
-		return
-	}
-
-	//turns into structure for faster retrieval
-	SpecifiedData = PartitionDataByFirstLetter(arraysWithData)
-}
-
-// list of DataEntry struct which all are of current year
-var OnlyCurrent []structures.DataEntry
-
-func init() {
-	var err error
-	//calls RetrieveAll function with current set to true
-	OnlyCurrent, err = RetrieveAll(structures.FILEPATH, true)
-	if err != nil {
-		log.Printf("Error retrieving countries from file: %v", err)
-	}
-}
-
-//Time complexity in O notation: O((log c)+d)
-//where c is the amount of different country first letter and d is the maximum number of countries sharing the same first letter
-//in country name because our algorithm stores presaves the countries with their information in maps based on the first letter
-//of the country name instead of in the CSV file. The maps are then placed placed in a BST based on the size of the map key.
-//It then goes into that map and goes trough all of the countries in that map and checking which matches with the specifications.
-
-//Example worst scenario:
-//20 countries with different first letter, where of each there are 10
-//just reading trough file: 200
-//our algorithm: (log₂(21)) - 1 ≈ 4 -> 4 + 10 = 14
+//Time complexity in O notation: O(1) to find the country and O(log y) to find a range of years,
+//where y is the number of years of data for the country, because the dataset keeps a map from iso code
+//and from name to the years of each country sorted by year.
+//The first letter BST used before had to go trough every line of the countries with the same first letter,
+//it is kept in bst_test.go, see BenchmarkExtractByBST and BenchmarkExtractByDataset for the difference.
 
 // extracts array of structs based on country name
 func ExtractByMap(country string) ([]structures.DataEntry, error) {
-	if SpecifiedData == nil {
-		return nil, fmt.Errorf("Error: no data loaded")
-	}
-	countryLetter := unicode.ToUpper(rune(country[0]))
-	//returns a map with the countries with that first letter
-	entriesWithKey := SearchBST(SpecifiedData, countryLetter)
-	if len(entriesWithKey) == 0 {
-		//no point in writing error for the server, since there isn't a problem with the server, just wrong input
-		//which will be dealt with in the function it is called from
-		return nil, nil
+	data := CurrentData()
+	if data == nil {
+		return nil, NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No data loaded")
 	}
-	//based on the countries with that first letter find the matching one
-	matchingCountries := FindMatchingCountries(entriesWithKey, country)
-	return matchingCountries, nil
+	//no point in writing error for the server if not found, since there isn't a problem with the server, just wrong input
+	//which will be dealt with in the function it is called from
+	return data.Country(country), nil
 }
 
-// searches the binary tree based on letter input
-func SearchBST(node *structures.BSTNode, letter rune) []structures.DataEntry {
-	//if node doesn't exist
-	if node == nil {
-		return nil
-	}
-	//go left is letter is smaller, right for bigger and do recusive call
-	if letter < node.Letter {
-		return SearchBST(node.Left, letter)
-	} else if letter > node.Letter {
-		return SearchBST(node.Right, letter)
+// reads the default metric of a csv file, if current only the latest year of each country
+func RetrieveAll(filePath string, current bool) ([]structures.DataEntry, error) {
+	table, err := ReadTable(filePath)
+	if err != nil {
+		return nil, err
 	}
-	return node.Data
-}
+	data := dataset.FromTable(table)
 
-// extracts the entries with given key
-func ExtractEntriesWithKey(partitionedData map[rune][]structures.DataEntry, key rune) []structures.DataEntry {
-	//takes to uppercode so regardless of the case of user input matches database
-	upperKey := unicode.ToUpper(key)
-
-	//creates storage for entries
-	entries := make([]structures.DataEntry, 0)
-	if upperData, ok := partitionedData[upperKey]; ok {
-		//appends with that key
-		entries = append(entries, upperData...)
+	//if current only keep the latest year of each country, which isn't the same year for every country
+	if current {
+		return data.Countries(), nil
 	}
 
-	return entries
-}
-
-func FindMatchingCountries(data []structures.DataEntry, country string) []structures.DataEntry {
-	//storage for matching countries
-	matchingCountries := make([]structures.DataEntry, 0)
-	countryLower := strings.ToLower(country)
-
-	//compares on a lowercase level so the cases is no problem
-	for _, entry := range data {
-		var entryCountryLower string
-		if IsCountryCode(countryLower) {
-			entryCountryLower = strings.ToLower(entry.CountryCode)
-		} else {
-			entryCountryLower = strings.ToLower(entry.Country)
-		}
-		if countryLower == entryCountryLower {
-			matchingCountries = append(matchingCountries, entry)
-		}
-	}
-	if len(matchingCountries) == 0 {
-		return nil
-	}
-
-	return matchingCountries
-}
-
-// based on lists of DataEntry structs returns them mapped into structured form
-func PartitionDataByFirstLetter(data []structures.DataEntry) *structures.BSTNode {
-	//sets root
-	var root *structures.BSTNode
-
-	for _, entry := range data {
-		//retrieves first letter
-		firstLetter := unicode.ToUpper(rune(entry.Country[0]))
-		//calls inserIntoBST for each map
-		root = InsertIntoBST(root, firstLetter, entry)
-	}
-	return root
+	//if successful return the data and nil error occurred
+	return data.All(), nil
 }
 
-func RetrieveAll(filePath string, current bool) ([]structures.DataEntry, error) {
-	//list of structs
-	var data []structures.DataEntry
+// header names of the columns which identify a row, and of the default metric, in lower case.
+// Both the renewables file and the full Our World in Data energy file are supported
+var countryHeaders = []string{"entity", "country"}
+var codeHeaders = []string{"code", "iso_code", "countrycode"}
+var yearHeaders = []string{"year"}
+var defaultMetricHeaders = []string{structures.DEFAULTMETRIC, "renewables (% equivalent primary energy)", "percentage"}
 
+// reads a csv file by the names in its header, every column which isn't the country, code or year is a metric
+func ReadTable(filePath string) (*dataset.Table, error) {
 	//opens given file
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -155,14 +70,41 @@ func RetrieveAll(filePath string, current bool) ([]structures.DataEntry, error)
 	//creates a new csv reader
 	reader := csv.NewReader(file)
 
-	//reads first line and don't do anything about it because it is headers
-	//skips the first lines so don't care about the value
-	_, err = reader.Read()
+	//reads the header to find which column is which
+	header, err := reader.Read()
 	if err != nil {
 		log.Printf("Error, not being able to read CSV file: %v", err)
 		return nil, err
 	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	countryColumn, countryFound := findColumn(columns, countryHeaders)
+	codeColumn, codeFound := findColumn(columns, codeHeaders)
+	yearColumn, yearFound := findColumn(columns, yearHeaders)
+	if !countryFound || !yearFound {
+		return nil, fmt.Errorf("Error: the CSV file has no country or year column")
+	}
+
+	//the metrics are the rest of the columns, the default metric has the same name regardless of the file
+	metricColumns := make(map[string]int)
+	for name, i := range columns {
+		if i == countryColumn || (codeFound && i == codeColumn) || i == yearColumn {
+			continue
+		}
+		metricColumns[name] = i
+	}
+	if i, ok := findColumn(columns, defaultMetricHeaders); ok {
+		for name, column := range metricColumns {
+			if column == i {
+				delete(metricColumns, name)
+			}
+		}
+		metricColumns[structures.DEFAULTMETRIC] = i
+	}
 
+	table := &dataset.Table{Columns: make(map[string][]float64, len(metricColumns))}
 	//infinite loop
 	for {
 		//reads line
@@ -179,55 +121,52 @@ func RetrieveAll(filePath string, current bool) ([]structures.DataEntry, error)
 		}
 
 		//new instance of struct
-		entry := structures.DataEntry{}
-
-		//fills in the variables in the struct
-		entry.Country = record[0]
-		entry.CountryCode = record[1]
+		entry := structures.DataEntry{Country: record[countryColumn]}
+		if codeFound {
+			entry.CountryCode = record[codeColumn]
+		}
 
 		//reads year as string and converts to int
-		year, err := strconv.Atoi(record[2])
+		year, err := strconv.Atoi(record[yearColumn])
 		if err != nil {
 			log.Printf("error parsing year: %v", err)
 			continue
 		}
-
 		entry.Year = year
 
-		//checks if current is true and if the entry year is not 2021, continue to the next iteration
-		if current && entry.Year != 2021 {
-			continue
+		//empty cells are common in the full file, they are NaN so the row is left out of that metric
+		for name, i := range metricColumns {
+			value, err := strconv.ParseFloat(record[i], 64)
+			if err != nil {
+				value = math.NaN()
+			}
+			table.Columns[name] = append(table.Columns[name], value)
 		}
+		table.Rows = append(table.Rows, entry)
+	}
 
-		//percentage
-		percentage, err := strconv.ParseFloat(record[3], 64)
-		if err != nil {
-			log.Printf("error parsing percentage: %v", err)
-			continue
+	//columns without a single number, like descriptions, aren't metrics
+	for name, values := range table.Columns {
+		numeric := false
+		for _, value := range values {
+			if !math.IsNaN(value) {
+				numeric = true
+				break
+			}
+		}
+		if !numeric {
+			delete(table.Columns, name)
 		}
-		entry.Percentage = percentage
-
-		//appends newly created struct to list of structs
-		data = append(data, entry)
 	}
-
-	//if successful return the data and nil error occurred
-	return data, nil
+	return table, nil
 }
 
-// take node and letter
-func InsertIntoBST(node *structures.BSTNode, letter rune, entry structures.DataEntry) *structures.BSTNode {
-	//if node is nil return the BST node with it's data
-	if node == nil {
-		return &structures.BSTNode{Data: []structures.DataEntry{entry}, Letter: letter}
-	}
-	//left for less right for greater else become root
-	if letter < node.Letter {
-		node.Left = InsertIntoBST(node.Left, letter, entry)
-	} else if letter > node.Letter {
-		node.Right = InsertIntoBST(node.Right, letter, entry)
-	} else {
-		node.Data = append(node.Data, entry)
+// index of the first of the names which is in the header
+func findColumn(columns map[string]int, names []string) (int, bool) {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i, true
+		}
 	}
-	return node
+	return 0, false
 }
@@ -1,9 +1,12 @@
 package functions
 
 import (
+	"reflect"
 	"testing"
 	"unicode"
 
+	"groupXX/countries"
+	"groupXX/dataset"
 	"groupXX/structures"
 )
 
@@ -95,9 +98,9 @@ var sampleData = []structures.DataEntry{
 	{Country: "Germany", CountryCode: "DE"},
 }
 
-func insertBSTNode(node *structures.BSTNode, data structures.DataEntry) *structures.BSTNode {
+func insertBSTNode(node *BSTNode, data structures.DataEntry) *BSTNode {
 	if node == nil {
-		newNode := &structures.BSTNode{
+		newNode := &BSTNode{
 			Letter: unicode.ToUpper(rune(data.Country[0])),
 			Data:   []structures.DataEntry{data},
 		}
@@ -117,8 +120,8 @@ func insertBSTNode(node *structures.BSTNode, data structures.DataEntry) *structu
 	return node
 }
 
-func setUpBST(data []structures.DataEntry) *structures.BSTNode {
-	var root *structures.BSTNode
+func setUpBST(data []structures.DataEntry) *BSTNode {
+	var root *BSTNode
 
 	for _, entry := range data {
 		root = insertBSTNode(root, entry)
@@ -139,7 +142,9 @@ func setUpPartitionedData(data []structures.DataEntry) map[rune][]structures.Dat
 }
 
 func TestExtractByMap(t *testing.T) {
-	SpecifiedData = setUpBST(sampleData)
+	defaultData := CurrentData()
+	defer SetData(defaultData)
+	SetData(dataset.New(sampleData))
 
 	testCases := []struct {
 		country          string
@@ -179,7 +184,7 @@ func TestExtractByMap(t *testing.T) {
 }
 
 func TestSearchBST(t *testing.T) {
-	SpecifiedData = setUpBST(sampleData)
+	root := setUpBST(sampleData)
 
 	testCases := []struct {
 		letter           rune
@@ -197,7 +202,7 @@ func TestSearchBST(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(string(tc.letter), func(t *testing.T) {
-			matchingEntries := SearchBST(SpecifiedData, tc.letter)
+			matchingEntries := SearchBST(root, tc.letter)
 
 			if len(matchingEntries) != len(tc.expectedMatching) {
 				t.Errorf("Expected %d matching entries, got %d", len(tc.expectedMatching), len(matchingEntries))
@@ -256,4 +261,165 @@ func TestPartitionDataByFirstLetter(t *testing.T) {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+func TestExtractByMapResolvesCountries(t *testing.T) {
+	resolver, err := countries.Load("../structures/countries.json")
+	if err != nil {
+		t.Fatalf("Loading countries failed: %v", err)
+	}
+	defaultResolver, defaultData := countries.Default(), CurrentData()
+	defer func() {
+		countries.SetDefault(defaultResolver)
+		SetData(defaultData)
+	}()
+
+	data := []structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021},
+		{Country: "Germany", CountryCode: "DEU", Year: 2021},
+		{Country: "Europe", CountryCode: "", Year: 2021},
+	}
+	countries.SetDefault(resolver)
+	SetData(dataset.New(data))
+
+	//every way of writing the country gives the same data
+	for _, input := range []string{"Norway", "nor", "NO", "Kingdom of Norway", "578"} {
+		matching, err := ExtractByMap(input)
+		if err != nil || len(matching) != 1 || matching[0].CountryCode != "NOR" {
+			t.Errorf("ExtractByMap(%q) returned %v, %v", input, matching, err)
+		}
+	}
+
+	//codes whose first letter differs from the name are found too
+	matching, _ := ExtractByMap("deu")
+	if len(matching) != 1 || matching[0].Country != "Germany" {
+		t.Errorf("ExtractByMap(\"deu\") returned %v", matching)
+	}
+
+	//aggregates are still found by name
+	matching, _ = ExtractByMap("europe")
+	if len(matching) != 1 || matching[0].Country != "Europe" {
+		t.Errorf("ExtractByMap(\"europe\") returned %v", matching)
+	}
+
+	if code, ok := NormaliseCountry("Kingdom of Norway"); !ok || code != "NOR" {
+		t.Errorf("NormaliseCountry() returned %s, expected NOR", code)
+	}
+	if code, ok := NormaliseCountry("europe"); !ok || code != "EUROPE" {
+		t.Errorf("NormaliseCountry() returned %s, expected EUROPE", code)
+	}
+	if code, ok := NormaliseCountry("Atlantis"); ok {
+		t.Errorf("NormaliseCountry() counted %s, expected it not to be counted", code)
+	}
+}
+
+// loads the real data for the benchmarks, skips if it isn't there
+func loadBenchmarkData(b *testing.B) []structures.DataEntry {
+	data, err := RetrieveAll("../structures/energyData.csv", false)
+	if err != nil {
+		b.Skipf("Energy data not available: %v", err)
+	}
+	return data
+}
+
+var benchmarkCountries = []string{"Norway", "nor", "United States", "Zimbabwe", "Africa", "Sweden"}
+
+func BenchmarkExtractByBST(b *testing.B) {
+	root := PartitionDataByFirstLetter(loadBenchmarkData(b))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		country := benchmarkCountries[i%len(benchmarkCountries)]
+		FindMatchingCountries(SearchBST(root, unicode.ToUpper(rune(country[0]))), country)
+	}
+}
+
+func BenchmarkExtractByDataset(b *testing.B) {
+	index := dataset.New(loadBenchmarkData(b))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Country(benchmarkCountries[i%len(benchmarkCountries)])
+	}
+}
+
+func BenchmarkRangeByBST(b *testing.B) {
+	root := PartitionDataByFirstLetter(loadBenchmarkData(b))
+	begin, end := 2000, 2010
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		country := benchmarkCountries[i%len(benchmarkCountries)]
+		var data []structures.DataEntry
+		for _, entry := range FindMatchingCountries(SearchBST(root, unicode.ToUpper(rune(country[0]))), country) {
+			if entry.Year >= begin && entry.Year <= end {
+				data = append(data, entry)
+			}
+		}
+	}
+}
+
+func BenchmarkRangeByDataset(b *testing.B) {
+	index := dataset.New(loadBenchmarkData(b))
+	begin, end := 2000, 2010
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Range(benchmarkCountries[i%len(benchmarkCountries)], &begin, &end)
+	}
+}
+
+func TestRetrieveAllCurrent(t *testing.T) {
+	//only the latest year of each country
+	data, err := RetrieveAll("./testData.csv", true)
+	if err != nil {
+		t.Fatalf("RetrieveAll failed: %v", err)
+	}
+	expected := []structures.DataEntry{
+		{Country: "United States", CountryCode: "USA", Year: 2021, Percentage: 10.6},
+		{Country: "Canada", CountryCode: "CAN", Year: 2021, Percentage: 29.8},
+		{Country: "Brazil", CountryCode: "BRA", Year: 2021, Percentage: 46.2},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Expected %v, got %v", expected, data)
+	}
+}
+
+func TestReadTable(t *testing.T) {
+	table, err := ReadTable("./testOwidData.csv")
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	expectedRows := []structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021},
+		{Country: "Norway", CountryCode: "NOR", Year: 2022},
+		{Country: "Europe", CountryCode: "", Year: 2021},
+	}
+	if !reflect.DeepEqual(table.Rows, expectedRows) {
+		t.Errorf("Expected rows %v, got %v", expectedRows, table.Rows)
+	}
+	//the text column isn't a metric
+	for _, metric := range []string{"population", structures.DEFAULTMETRIC, "solar_share_energy", "energy_per_capita"} {
+		if len(table.Columns[metric]) != 3 {
+			t.Errorf("Expected 3 values of %s, got %v", metric, table.Columns[metric])
+		}
+	}
+	if _, ok := table.Columns["note"]; ok || len(table.Columns) != 4 {
+		t.Errorf("Expected 4 metrics, got %v", table.Columns)
+	}
+
+	//rows without a value are left out of the metric
+	data := dataset.FromTable(table)
+	if data.Len() != 2 {
+		t.Errorf("Expected 2 renewables entries, got %v", data.All())
+	}
+	solar, ok := data.Metric("Solar_Share_Energy")
+	if !ok || solar.Len() != 2 || solar.Current("NOR")[0].Percentage != 0.03 {
+		t.Errorf("Expected 2 solar entries with 0.03 for 2022, got %v", solar.All())
+	}
+	if _, ok := data.Metric("wind_share_energy"); ok {
+		t.Errorf("Expected wind_share_energy to be missing")
+	}
+
+	//the renewables file has the default metric under another name
+	table, err = ReadTable("./testData.csv")
+	if err != nil || len(table.Columns[structures.DEFAULTMETRIC]) != 4 {
+		t.Errorf("Expected the percentage column to be the default metric, got %v, %v", table, err)
+	}
+}
@@ -0,0 +1,133 @@
+package functions
+
+import (
+	"sort"
+	"strings"
+
+	"groupXX/countries"
+	"groupXX/structures"
+)
+
+// ranks of the match types, lower is better
+var matchRank = map[string]int{
+	structures.MATCH_EXACT:     0,
+	structures.MATCH_PREFIX:    1,
+	structures.MATCH_SUBSTRING: 2,
+	structures.MATCH_FUZZY:     3,
+}
+
+// searches the names and codes in the data, best matches first, at most limit results
+func SearchCountries(data []structures.DataEntry, query string, limit int) []structures.SearchResult {
+	query = countries.Normalise(query)
+	results := make([]structures.SearchResult, 0)
+	if query == "" {
+		return results
+	}
+	//aliases like "UK" don't look like the name in the data, so the resolved code counts as an exact match
+	resolved := strings.ToLower(countries.ISO3(query))
+
+	seen := make(map[string]bool)
+	for _, entry := range data {
+		//the data has one line per year, each country is only ranked once
+		if seen[entry.Country] {
+			continue
+		}
+		seen[entry.Country] = true
+
+		name := countries.Normalise(entry.Country)
+		code := strings.ToLower(entry.CountryCode)
+		result := structures.SearchResult{Name: entry.Country, CountryCode: entry.CountryCode}
+		switch {
+		case query == name || query == code || (resolved != "" && resolved == code):
+			result.Match = structures.MATCH_EXACT
+		case strings.HasPrefix(name, query) || (code != "" && strings.HasPrefix(code, query)):
+			result.Match = structures.MATCH_PREFIX
+			result.Distance = len(name) - len(query)
+		case strings.Contains(name, query):
+			result.Match = structures.MATCH_SUBSTRING
+			result.Distance = len(name) - len(query)
+		default:
+			distance := Levenshtein(query, name)
+			if code != "" {
+				if codeDistance := Levenshtein(query, code); codeDistance < distance {
+					distance = codeDistance
+				}
+			}
+			//allows roughly one typo for every three letters
+			if distance > maxDistance(query) {
+				continue
+			}
+			result.Match = structures.MATCH_FUZZY
+			result.Distance = distance
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if matchRank[results[i].Match] != matchRank[results[j].Match] {
+			return matchRank[results[i].Match] < matchRank[results[j].Match]
+		}
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		return results[i].Name < results[j].Name
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// names of the countries closest to a search which found nothing
+func Suggestions(data []structures.DataEntry, query string) []string {
+	suggestions := make([]string, 0)
+	for _, result := range SearchCountries(data, query, 0) {
+		//an exact match means the country exists, but nothing was found for the rest of the search
+		if result.Match == structures.MATCH_EXACT {
+			continue
+		}
+		suggestions = append(suggestions, result.Name)
+		if len(suggestions) == structures.MAXSUGGESTIONS {
+			break
+		}
+	}
+	return suggestions
+}
+
+// how many edits a query of that length can be from a name and still match
+func maxDistance(query string) int {
+	distance := len([]rune(query)) / 3
+	if distance < 1 {
+		return 1
+	}
+	return distance
+}
+
+// number of single letter insertions, deletions and substitutions needed to turn a into b
+func Levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	//only the previous row of the matrix is needed
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,58 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/structures"
+)
+
+var searchData = []structures.DataEntry{
+	{Country: "Norway", CountryCode: "NOR", Year: 2020},
+	{Country: "Norway", CountryCode: "NOR", Year: 2021},
+	{Country: "North Macedonia", CountryCode: "MKD", Year: 2021},
+	{Country: "Sweden", CountryCode: "SWE", Year: 2021},
+	{Country: "South Africa", CountryCode: "ZAF", Year: 2021},
+	{Country: "Europe", CountryCode: "", Year: 2021},
+}
+
+func TestSearchCountries(t *testing.T) {
+	//exact before prefix, prefix before substring
+	results := SearchCountries(searchData, "nor", 0)
+	assert.Equal(t, []structures.SearchResult{
+		{Name: "Norway", CountryCode: "NOR", Match: structures.MATCH_EXACT},
+		{Name: "North Macedonia", CountryCode: "MKD", Match: structures.MATCH_PREFIX, Distance: 12},
+	}, results)
+
+	results = SearchCountries(searchData, "africa", 0)
+	assert.Equal(t, []structures.SearchResult{{Name: "South Africa", CountryCode: "ZAF", Match: structures.MATCH_SUBSTRING, Distance: 6}}, results)
+
+	//typos are found by their distance
+	results = SearchCountries(searchData, "Swedn", 0)
+	assert.Equal(t, []structures.SearchResult{{Name: "Sweden", CountryCode: "SWE", Match: structures.MATCH_FUZZY, Distance: 1}}, results)
+
+	//one line per country even though the data has one per year, and the limit is respected
+	results = SearchCountries(searchData, "o", 1)
+	assert.Len(t, results, 1)
+
+	assert.Empty(t, SearchCountries(searchData, "", 0))
+	assert.Empty(t, SearchCountries(searchData, "Atlantis", 0))
+}
+
+func TestSuggestions(t *testing.T) {
+	assert.Equal(t, []string{"Norway"}, Suggestions(searchData, "Norwya"))
+	assert.Equal(t, []string{"Europe"}, Suggestions(searchData, "eurpoe"))
+	//the country exists, so it isn't suggested
+	assert.Equal(t, []string{}, Suggestions(searchData, "Norway"))
+	assert.Equal(t, []string{}, Suggestions(searchData, "Atlantis"))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, Levenshtein("norway", "norway"))
+	assert.Equal(t, 1, Levenshtein("norwy", "norway"))
+	assert.Equal(t, 2, Levenshtein("norwya", "norway"))
+	assert.Equal(t, 3, Levenshtein("kitten", "sitting"))
+	assert.Equal(t, 6, Levenshtein("", "sweden"))
+	assert.Equal(t, 1, Levenshtein("åland", "aland"))
+}
@@ -0,0 +1,117 @@
+package functions
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"groupXX/structures"
+)
+
+// computes the trend statistics of the entries of one country, sorted by year as ReadCountryInfo returns them.
+// At least two years are needed for there to be a trend
+func Trend(data []structures.DataEntry, metric string) (structures.Trend, error) {
+	if len(data) < 2 {
+		return structures.Trend{}, NewError(http.StatusBadRequest, structures.ERR_INSUFFICIENT_DATA,
+			"At least two years are needed for a trend, found "+strconv.Itoa(len(data)))
+	}
+	first, last := data[0], data[len(data)-1]
+	trend := structures.Trend{
+		Country:        first.Country,
+		CountryCode:    first.CountryCode,
+		Metric:         metric,
+		Years:          len(data),
+		First:          structures.YearValue{Year: first.Year, Value: first.Percentage},
+		Last:           structures.YearValue{Year: last.Year, Value: last.Percentage},
+		Min:            structures.YearValue{Year: first.Year, Value: first.Percentage},
+		Max:            structures.YearValue{Year: first.Year, Value: first.Percentage},
+		AbsoluteChange: last.Percentage - first.Percentage,
+	}
+
+	if first.Percentage != 0 {
+		relative := 100 * trend.AbsoluteChange / first.Percentage
+		trend.RelativeChange = &relative
+	}
+	if first.Percentage > 0 && last.Percentage > 0 && last.Year > first.Year {
+		cagr := 100 * (math.Pow(last.Percentage/first.Percentage, 1/float64(last.Year-first.Year)) - 1)
+		trend.CAGR = &cagr
+	}
+
+	//the lowest and highest year
+	for _, entry := range data {
+		if entry.Percentage < trend.Min.Value {
+			trend.Min = structures.YearValue{Year: entry.Year, Value: entry.Percentage}
+		}
+		if entry.Percentage > trend.Max.Value {
+			trend.Max = structures.YearValue{Year: entry.Year, Value: entry.Percentage}
+		}
+	}
+
+	line := fitLine(data)
+	trend.Slope, trend.Intercept = line.slope, line.intercept
+	if line.syy > 0 {
+		rSquared := line.sxy * line.sxy / (line.sxx * line.syy)
+		trend.RSquared = &rSquared
+	}
+
+	//change per year between each pair of years in the data, divided by the gap if years are missing
+	changes := make([]float64, 0, len(data)-1)
+	for i := 1; i < len(data); i++ {
+		if gap := data[i].Year - data[i-1].Year; gap > 0 {
+			changes = append(changes, (data[i].Percentage-data[i-1].Percentage)/float64(gap))
+		}
+	}
+	trend.Volatility = standardDeviation(changes)
+	return trend, nil
+}
+
+// least squares line of the value against the year, with the sums of squares around the means
+type line struct {
+	slope, intercept float64
+	n, meanX         float64
+	sxx, sxy, syy    float64
+}
+
+func fitLine(data []structures.DataEntry) line {
+	var sumX, sumY float64
+	for _, entry := range data {
+		sumX += float64(entry.Year)
+		sumY += entry.Percentage
+	}
+	l := line{n: float64(len(data))}
+	l.meanX = sumX / l.n
+	meanY := sumY / l.n
+	for _, entry := range data {
+		dx, dy := float64(entry.Year)-l.meanX, entry.Percentage-meanY
+		l.sxx += dx * dx
+		l.sxy += dx * dy
+		l.syy += dy * dy
+	}
+	if l.sxx > 0 {
+		l.slope = l.sxy / l.sxx
+	}
+	l.intercept = meanY - l.slope*l.meanX
+	return l
+}
+
+// value of the line for a year
+func (l line) at(year int) float64 {
+	return l.intercept + l.slope*float64(year)
+}
+
+// population standard deviation, 0 for no values
+func standardDeviation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)))
+}
@@ -0,0 +1,50 @@
+package functions
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/structures"
+)
+
+func TestTrend(t *testing.T) {
+	data := []structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2018, Percentage: 40},
+		{Country: "Norway", CountryCode: "NOR", Year: 2019, Percentage: 50},
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 45},
+		{Country: "Norway", CountryCode: "NOR", Year: 2022, Percentage: 90},
+	}
+
+	trend, err := Trend(data, structures.DEFAULTMETRIC)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, trend.Years)
+	assert.Equal(t, structures.YearValue{Year: 2018, Value: 40}, trend.First)
+	assert.Equal(t, structures.YearValue{Year: 2022, Value: 90}, trend.Last)
+	assert.Equal(t, structures.YearValue{Year: 2018, Value: 40}, trend.Min)
+	assert.Equal(t, structures.YearValue{Year: 2022, Value: 90}, trend.Max)
+	assert.Equal(t, 50.0, trend.AbsoluteChange)
+	assert.Equal(t, 125.0, *trend.RelativeChange)
+	assert.InDelta(t, 100*(math.Pow(90.0/40, 0.25)-1), *trend.CAGR, 1e-9)
+
+	//around the means 2019.75 and 56.25 the sums of squares are 8.75 for the years and 1568.75 for the values,
+	//and the sum of products is 106.25
+	assert.InDelta(t, 106.25/8.75, trend.Slope, 1e-9)
+	assert.InDelta(t, 56.25-trend.Slope*2019.75, trend.Intercept, 1e-6)
+	assert.InDelta(t, 106.25*106.25/(8.75*1568.75), *trend.RSquared, 1e-9)
+
+	//the changes per year are 10, -5 and 22.5 for each of the two years from 2020 to 2022
+	assert.InDelta(t, standardDeviation([]float64{10, -5, 22.5}), trend.Volatility, 1e-9)
+
+	//a flat trend has no R² and no growth from zero
+	trend, err = Trend([]structures.DataEntry{{Year: 2020}, {Year: 2021}}, structures.DEFAULTMETRIC)
+	assert.NoError(t, err)
+	assert.Nil(t, trend.RSquared)
+	assert.Nil(t, trend.RelativeChange)
+	assert.Nil(t, trend.CAGR)
+	assert.Equal(t, 0.0, trend.Slope)
+
+	_, err = Trend(data[:1], structures.DEFAULTMETRIC)
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package functions
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"groupXX/countries"
+	"groupXX/storage"
+	"groupXX/structures"
+	"groupXX/webhooks"
+)
+
+// returns the iso code of the country the search matches, so the same country is counted once however it is written,
+// and if it is something to count. Aggregates like Europe have no code and are counted by their name in the data,
+// an empty search is every country and anything else isn't counted
+func NormaliseCountry(searchInput string) (string, bool) {
+	searchInput = strings.TrimSpace(searchInput)
+	if searchInput == "" {
+		return structures.ALLCOUNTRIES, true
+	}
+	if code := countries.ISO3(searchInput); code != "" {
+		return code, true
+	}
+	matching, err := ExtractByMap(searchInput)
+	if err != nil || len(matching) == 0 {
+		return "", false
+	}
+	if matching[0].CountryCode != "" {
+		return matching[0].CountryCode, true
+	}
+	return strings.ToUpper(matching[0].Country), true
+}
+
+// a call counted for a country, and the count after it
+type countedCall struct {
+	country string
+	count   int64
+}
+
+// Updates the call count of every search and queues the webhooks which are to be invocated. Searches which aren't
+// counted by NormaliseCountry are left out, and the webhooks are loaded once for all of the searches
+func UpdateCalls(searches ...string) error {
+	ctx := context.Background()
+
+	//increments call by 1 in the storage, so the count is shared between restarts and instances
+	var calls []countedCall
+	for _, search := range searches {
+		country, ok := NormaliseCountry(search)
+		if !ok {
+			continue
+		}
+		count, err := storage.DB.IncrementCalls(ctx, country)
+		if err != nil {
+			return err
+		}
+		calls = append(calls, countedCall{country: country, count: count})
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+
+	registrations, err := storage.DB.GetAllWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	//the call webhooks by their country, so each is only normalised once
+	byCountry := make(map[string][]structures.WebhookRegistration)
+	for _, registration := range registrations {
+		wh := registration.Webhook
+		//threshold webhooks are checked when the data is loaded instead
+		if (wh.Event != "" && wh.Event != structures.EVENT_CALLS) || wh.Calls <= 0 {
+			continue
+		}
+		if country, ok := NormaliseCountry(wh.Country); ok {
+			byCountry[country] = append(byCountry[country], registration)
+		}
+	}
+
+	for _, call := range calls {
+		for _, registration := range byCountry[call.country] {
+			wh := registration.Webhook
+			//if mod is = 0
+			if call.count%int64(wh.Calls) != 0 {
+				continue
+			}
+			//the payload is the webhook itself, without the secret
+			payload := wh
+			payload.Secret = ""
+			jsonData, err := json.Marshal(payload)
+			if err != nil {
+				return err
+			}
+			//delivered in the background, so a subscriber which is down doesn't fail the request
+			webhooks.Default.Enqueue(registration.ID, wh, jsonData)
+		}
+	}
+	return nil
+}
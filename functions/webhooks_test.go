@@ -0,0 +1,59 @@
+package functions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+func TestUpdateCalls(t *testing.T) {
+	var invoked int32
+	// Create a new test server which counts the invocations
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&invoked, 1)
+	}))
+
+	// Ensure that the test server is closed after the test
+	defer ts.Close()
+
+	defaultData := CurrentData()
+	defer SetData(defaultData)
+	SetData(dataset.New([]structures.DataEntry{{Country: "Germany", CountryCode: "DEU", Year: 2021, Percentage: 19.6}}))
+
+	// register a webhook in an empty in memory store
+	storage.DB = storage.NewMemoryStore()
+	id, err := storage.DB.StoreWebhook(context.Background(), structures.Webhook{URL: ts.URL, Country: "germany", Calls: 2})
+	assert.NoError(t, err)
+
+	// Call the function that we want to test, the webhook should only fire on every second call
+	for i := 0; i < 4; i++ {
+		err = UpdateCalls("germany")
+		assert.NoError(t, err)
+	}
+
+	// the deliveries happen in the background, so wait until both are recorded
+	deadline := time.Now().Add(time.Second)
+	for {
+		deliveries, _ := storage.DB.GetDeliveries(context.Background(), id)
+		if len(deliveries) == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&invoked))
+
+	// only countries which are known are counted, several at once
+	assert.NoError(t, UpdateCalls("deu", "Atlantis", ""))
+	calls, err := storage.DB.GetAllCalls(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"DEU": 5, structures.ALLCOUNTRIES: 1}, calls)
+}
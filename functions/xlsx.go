@@ -0,0 +1,109 @@
+package functions
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// the parts of a workbook with one sheet, the smallest file Excel and LibreOffice open without complaints.
+// The sheet is the only part which changes, so the others are written as they are
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="energy" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// writes the header and rows as a workbook with one sheet, numbers are number cells so they can be calculated with
+// and everything else is text
+func WriteXLSX(w io.Writer, header []string, rows [][]interface{}) error {
+	//the zip is built first, so nothing is written if it fails
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, part := range xlsxParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var content bytes.Buffer
+	content.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	headerRow := make([]interface{}, len(header))
+	for i, name := range header {
+		headerRow[i] = name
+	}
+	writeXLSXRow(&content, 1, headerRow)
+	for i, row := range rows {
+		writeXLSXRow(&content, i+2, row)
+	}
+	content.WriteString(`</sheetData></worksheet>`)
+	if _, err := sheet.Write(content.Bytes()); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	_, err = buffer.WriteTo(w)
+	return err
+}
+
+func writeXLSXRow(content *bytes.Buffer, number int, row []interface{}) {
+	content.WriteString(`<row r="` + strconv.Itoa(number) + `">`)
+	for i, cell := range row {
+		if cell == nil {
+			continue
+		}
+		reference := xlsxColumn(i) + strconv.Itoa(number)
+		switch value := cell.(type) {
+		case int, float64:
+			content.WriteString(`<c r="` + reference + `"><v>` + formatCell(value) + `</v></c>`)
+		case bool:
+			boolean := "0"
+			if value {
+				boolean = "1"
+			}
+			content.WriteString(`<c r="` + reference + `" t="b"><v>` + boolean + `</v></c>`)
+		default:
+			content.WriteString(`<c r="` + reference + `" t="inlineStr"><is><t>`)
+			xml.EscapeText(content, []byte(formatCell(value)))
+			content.WriteString(`</t></is></c>`)
+		}
+	}
+	content.WriteString(`</row>`)
+}
+
+// name of a column in a cell reference, A to Z and then AA, AB and so on
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,48 @@
+package functions
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteXLSX(&buffer, []string{"Entity", "Code", "Year", "value"}, [][]interface{}{
+		{"Bosnia & Herzegovina", "BIH", 2021, 30.25},
+		{"Sweden", "SWE", 2021, nil},
+	})
+	assert.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	assert.NoError(t, err)
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(reader)
+		assert.NoError(t, err)
+		reader.Close()
+		parts[file.Name] = string(content)
+	}
+	assert.Len(t, parts, 5)
+	assert.Contains(t, parts, "[Content_Types].xml")
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" t="inlineStr"><is><t>Entity</t></is></c>`)
+	//text is escaped and numbers are number cells
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t>Bosnia &amp; Herzegovina</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="C2"><v>2021</v></c><c r="D2"><v>30.25</v></c>`)
+	//a missing value is an empty cell
+	assert.Contains(t, sheet, `<c r="C3"><v>2021</v></c></row>`)
+}
+
+func TestXLSXColumn(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumn(0))
+	assert.Equal(t, "Z", xlsxColumn(25))
+	assert.Equal(t, "AA", xlsxColumn(26))
+	assert.Equal(t, "BA", xlsxColumn(52))
+}
@@ -8,7 +8,10 @@ require (
 	github.com/gomarkdown/markdown v0.0.0-20230322041520-c84983bdbf2a
 	github.com/google/uuid v1.3.0
 	github.com/stretchr/testify v1.8.2
+	go.etcd.io/bbolt v1.3.7
 	golang.org/x/oauth2 v0.7.0 // indirect
+	golang.org/x/text v0.9.0
 	google.golang.org/api v0.116.0
 	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/grpc v1.54.0
 )
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func ReloadHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if POST then forward to function, else write to the user that only POST is allowed
+	switch r.Method {
+	case http.MethodPost:
+		ReloadPostHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodPost))
+		return
+	}
+}
+
+// loads the data file again, the previous data is kept if the new one isn't valid
+func ReloadPostHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	//the request has to carry ADMIN_TOKEN as a bearer token, and without a token nobody may reload
+	token := os.Getenv("ADMIN_TOKEN")
+	if token == "" {
+		functions.WriteError(w, r, functions.NewError(http.StatusForbidden, structures.ERR_FORBIDDEN,
+			"Reloading is turned off, ADMIN_TOKEN isn't set"))
+		return
+	}
+	given := []byte(r.Header.Get("Authorization"))
+	if subtle.ConstantTimeCompare(given, []byte("Bearer "+token)) != 1 {
+		functions.WriteError(w, r, functions.NewError(http.StatusUnauthorized, structures.ERR_UNAUTHORIZED,
+			"Missing or wrong admin token"))
+		return
+	}
+
+	data, err := functions.Reload(context.Background())
+	if err != nil {
+		functions.WriteError(w, r, functions.NewError(http.StatusInternalServerError, structures.ERR_INTERNAL,
+			"Error reloading data, the previous data is still in use: "+err.Error()))
+		return
+	}
+
+	functions.PrintData(w, structures.ReloadResult{
+		Entries:   data.Len(),
+		Countries: len(data.Countries()),
+		Version:   data.Version(),
+	})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/functions"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+func TestReloadPostHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "energyData.csv")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("Entity,Code,Year,Renewables (% equivalent primary energy)\nNorway,NOR,2021,71.5\n"), 0644))
+
+	defaultPath, defaultData := functions.DataPath, functions.CurrentData()
+	defer func() {
+		functions.DataPath = defaultPath
+		functions.SetData(defaultData)
+	}()
+	functions.DataPath = path
+	storage.DB = storage.NewMemoryStore()
+
+	//without a token nobody may reload
+	os.Unsetenv("ADMIN_TOKEN")
+	rr := httptest.NewRecorder()
+	ReloadHandler(rr, httptest.NewRequest(http.MethodPost, structures.RELOAD_PATH, nil))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	os.Setenv("ADMIN_TOKEN", "letmein")
+	defer os.Unsetenv("ADMIN_TOKEN")
+
+	rr = httptest.NewRecorder()
+	ReloadHandler(rr, httptest.NewRequest(http.MethodPost, structures.RELOAD_PATH, nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req := httptest.NewRequest(http.MethodPost, structures.RELOAD_PATH, nil)
+	req.Header.Set("Authorization", "Bearer letmein")
+	rr = httptest.NewRecorder()
+	ReloadHandler(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	result := structures.ReloadResult{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Entries)
+	assert.Equal(t, 1, result.Countries)
+	assert.Equal(t, functions.CurrentData().Version(), result.Version)
+
+	//a broken file keeps the data in use
+	assert.NoError(t, ioutil.WriteFile(path, []byte("Entity,Code,Year,Renewables (% equivalent primary energy)\n"), 0644))
+	rr = httptest.NewRecorder()
+	ReloadHandler(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, result.Version, functions.CurrentData().Version())
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func CompareHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		CompareGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// compares ?countries= (a comma separated list) from ?begin= to ?end=, both optional like on the history endpoint.
+// ?baseline= is one of the countries, which the others get the difference to
+func CompareGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	queryParams := r.URL.Query()
+
+	var countries []string
+	for _, country := range strings.Split(queryParams.Get("countries"), ",") {
+		if country = strings.TrimSpace(country); country != "" {
+			countries = append(countries, country)
+		}
+	}
+	if len(countries) == 0 {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_MISSING_PARAMETER,
+			"Missing countries, expected ?countries= with a comma separated list"))
+		return
+	}
+	if len(countries) > structures.MAXCOMPARECOUNTRIES {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"At most "+strconv.Itoa(structures.MAXCOMPARECOUNTRIES)+" countries can be compared"))
+		return
+	}
+
+	begin, end, ok := requestedYears(w, r)
+	if !ok {
+		return
+	}
+
+	_, metric, ok := requestedMetric(w, r)
+	if !ok {
+		return
+	}
+
+	//?format= or the Accept header picks csv, ndjson or xlsx instead of JSON
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
+	//reads every country, the same country given twice (like "nor" and "norway") is only compared once
+	var identities []structures.DataEntry
+	var series [][]structures.DataEntry
+	seen := make(map[string]bool)
+	for _, country := range countries {
+		identity, ok := identify(country)
+		if !ok {
+			NotFound(w, r, country)
+			return
+		}
+		if seen[countryKey(identity)] {
+			continue
+		}
+		seen[countryKey(identity)] = true
+
+		data, err := functions.ReadMetricInfo(metric, country, false, begin, end)
+		if err != nil {
+			log.Printf("Error reading CSV file: %v", err)
+			functions.WriteError(w, r, err)
+			return
+		}
+		identities = append(identities, identity)
+		series = append(series, data)
+	}
+
+	baseline := -1
+	if baselineStr := strings.TrimSpace(queryParams.Get("baseline")); baselineStr != "" {
+		if identity, ok := identify(baselineStr); ok {
+			for i := range identities {
+				if countryKey(identities[i]) == countryKey(identity) {
+					baseline = i
+				}
+			}
+		}
+		if baseline < 0 {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"The baseline '"+baselineStr+"' has to be one of the compared countries"))
+			return
+		}
+	}
+
+	//every country compared is counted once
+	compared := make([]string, len(identities))
+	for i := range identities {
+		compared[i] = countryKey(identities[i])
+	}
+	countCalls(compared, nil)
+	functions.PrintFormat(w, functions.Compare(identities, series, metric, baseline), format)
+}
+
+// the name and code of the country a search is for, and if it is in the data
+func identify(search string) (structures.DataEntry, bool) {
+	matching := functions.CurrentData().Country(search)
+	if len(matching) == 0 {
+		return structures.DataEntry{}, false
+	}
+	return structures.DataEntry{Country: matching[0].Country, CountryCode: matching[0].CountryCode}, true
+}
+
+// the iso code of a country, or the name of regions without one
+func countryKey(country structures.DataEntry) string {
+	if country.CountryCode != "" {
+		return country.CountryCode
+	}
+	return country.Country
+}
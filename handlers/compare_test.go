@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TestCompareGetHandler(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 70},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 72},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 52},
+	}))
+
+	rr := httptest.NewRecorder()
+	CompareHandler(rr, httptest.NewRequest(http.MethodGet, structures.COMPARE_PATH+
+		"?countries=nor,swe,norway&baseline=sweden", nil))
+	comparison := structures.Comparison{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &comparison))
+	assert.Equal(t, []int{2020, 2021}, comparison.Years)
+	assert.Equal(t, "SWE", comparison.Baseline)
+	assert.Len(t, comparison.Countries, 2)
+	assert.Equal(t, []int{2020}, comparison.Countries[1].Missing)
+	assert.Equal(t, 20.0, *comparison.Countries[0].Difference[1])
+
+	rr = httptest.NewRecorder()
+	CompareHandler(rr, httptest.NewRequest(http.MethodGet, structures.COMPARE_PATH+"?countries=nor,swe&begin=2021", nil))
+	comparison = structures.Comparison{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &comparison))
+	assert.Equal(t, []int{2021}, comparison.Years)
+	assert.Empty(t, comparison.Baseline)
+
+	for _, query := range []string{"", "?countries=nor&begin=x", "?countries=nor,swe&baseline=dnk"} {
+		rr = httptest.NewRecorder()
+		CompareHandler(rr, httptest.NewRequest(http.MethodGet, structures.COMPARE_PATH+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+
+	rr = httptest.NewRecorder()
+	CompareHandler(rr, httptest.NewRequest(http.MethodGet, structures.COMPARE_PATH+"?countries=nor,atlantis", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
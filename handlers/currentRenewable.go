@@ -1,13 +1,12 @@
 package handlers
 
 import (
-	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 
-	"groupXX/firebase"
 	"groupXX/functions"
+	"groupXX/regions"
 	"groupXX/structures"
 )
 
@@ -17,21 +16,17 @@ func CurrentHandler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		CurrentGetHandler(w, r)
 	default:
-		http.Error(w, "REST Method '"+r.Method+"' not supported. Currently only '"+http.MethodGet+
-			"' are supported.", http.StatusNotImplemented)
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
 		return
 	}
 }
 
+//parses the country and ?neighbours= of the request, writes the error to the user and returns it if they can't be parsed
 func CurrentGetRequest(w http.ResponseWriter, r *http.Request) (country string, neighbours bool, err error) {
 	basePath := structures.RENEWABLECURRENT_PATH
 
 	//returns path other than basePath
 	country = r.URL.Path[len(basePath):]
-	err = firebase.UpdateCalls(country)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
 	//returns parsed query parameters in a map
 	queryParams := r.URL.Query()
 	//gets the neighbours query from the map
@@ -45,7 +40,8 @@ func CurrentGetRequest(w http.ResponseWriter, r *http.Request) (country string,
 		//if so turn into bool
 		neighbours, err = strconv.ParseBool(neighboursStr)
 		if err != nil {
-			http.Error(w, "Error parsing neighbours value from string to bool: "+err.Error(), http.StatusBadRequest)
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing neighbours value from string to bool: "+err.Error()))
 			return
 		}
 	}
@@ -57,52 +53,105 @@ func CurrentGetHandler(w http.ResponseWriter, r *http.Request) {
 
 	countryName, neighbours, err := CurrentGetRequest(w,r)
 	if err != nil{
-		log.Printf("Error parsing URL")
+		return
 	}
 	
-	//calls file to return the countries as a struct with the specification of country name, true because it only returns
-	//the current value 2021 not history, nil and nil for the optional value of begin and end year of search
-	data, err := functions.ReadCountryInfo(w, countryName, true, nil, nil)
+	//by default the current value is the latest year of each country, with ?latest=global it is the latest year
+	//of any country, so countries which haven't reported that year are left out
+	latest := r.URL.Query().Get("latest")
+	if latest != "" && latest != structures.LATEST_COUNTRY && latest != structures.LATEST_GLOBAL {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Error parsing latest value, expected '"+structures.LATEST_COUNTRY+"' or '"+structures.LATEST_GLOBAL+"'"))
+		return
+	}
+	//?metric= shows another metric in the data than the renewables percentage
+	metricData, metric, ok := requestedMetric(w, r)
+	if !ok {
+		return
+	}
+	aggregates, ok := includeAggregates(w, r)
+	if !ok {
+		return
+	}
+	depth, ok := requestedDepth(w, r)
+	if !ok {
+		return
+	}
+	//?page= and ?limit= split long lists like every country, and ?envelope=true adds what the list is
+	page, limit, ok := requestedPage(w, r)
+	if !ok {
+		return
+	}
+	envelope, ok := requestedEnvelope(w, r)
+	if !ok {
+		return
+	}
+	//?format= or the Accept header picks csv, ndjson or xlsx instead of JSON
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
+	latestYear := metricData.LatestYear()
+	readCurrent := func(country string) ([]structures.DataEntry, error) {
+		if latest == structures.LATEST_GLOBAL {
+			return functions.ReadMetricInfo(metric, country, false, &latestYear, &latestYear)
+		}
+		return functions.ReadMetricInfo(metric, country, true, nil, nil)
+	}
+
+	//calls file to return the countries as a struct with the specification of country name
+	data, err := readCurrent(countryName)
 	if err != nil {
 		log.Printf("Error reading CSV file: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+	//?aggregates=false lists only countries, without regions like Europe
+	if countryName == "" && !aggregates {
+		data = regions.ExcludeAggregates(data)
 	}
 	if data == nil {
-		fmt.Fprint(w, "No return for the given search found")
-	} else {
-
-		//if the user wants to see neighbours aswell
-		if neighbours {
-			for _, entry := range data {
-				//calls function to retrieve neighbours
-				neighbours, err := functions.RetrieveNeighbours(w, entry.Country)
-
-				if err != nil {
-					log.Printf("Error retrieving neighbours: %v", err)
-					http.Error(w, "Error retrieving neighbours: "+err.Error(), http.StatusInternalServerError)
-					return
-				}
+		NotFound(w, r, countryName)
+		return
+	}
 
-				//if the country has neighbours loop trough neighbours
-				if len(neighbours) > 0 {
-					for _, currentNeighbour := range neighbours {
-						//update number of calls
-						err = firebase.UpdateCalls(currentNeighbour)
-						if err != nil {
-							http.Error(w, err.Error(), http.StatusInternalServerError)
-						}
-						neigh, err := functions.ReadCountryInfo(w, currentNeighbour, true, nil, nil)
-						if err != nil {
-							log.Printf("Error reading CSV file: %v", err)
-						} 
+	//if the user wants to see neighbours aswell, within ?depth= land borders of the country
+	var distances map[string]int
+	var warnings []string
+	if neighbours && countryName != "" {
+		data, distances, warnings, err = withNeighbours(r, data, depth, readCurrent)
+		if err != nil {
+			log.Printf("Error retrieving neighbours: %v", err)
+			functions.WriteError(w, r, err)
+			return
+		}
+	}
+	countCalls([]string{countryName}, distances)
+	data, meta := paginate(w, r, data, page, limit)
 
-						//in cases where the neighbour counrty doesn't exist in the csv file
-						if len(neigh) > 0 {
-							data = append(data, neigh[0])
-						}
-					}
-				}
+	//every entry tells which year it is from and if it is behind the latest year in the data
+	var entries interface{}
+	if metric == structures.DEFAULTMETRIC {
+		marked := functions.MarkStaleness(data, latestYear)
+		for i := range marked {
+			if distance, ok := distances[countryKey(marked[i].DataEntry)]; ok {
+				marked[i].Distance = &distance
 			}
 		}
-		functions.PrintData(w, data)
+		entries = marked
+	} else {
+		marked := functions.MarkMetricStaleness(data, metric, latestYear)
+		for i := range marked {
+			if distance, ok := distances[countryKey(data[i])]; ok {
+				marked[i].Distance = &distance
+			}
+		}
+		entries = marked
+	}
+
+	if distances == nil {
+		printList(w, entries, meta, envelope, format)
+	} else {
+		printList(w, neighbourResponse(entries, warnings, envelope), meta, envelope, format)
 	}
 }
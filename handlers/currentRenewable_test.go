@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"net/http/httptest"
 
 	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries"
+	"groupXX/countries/countriestest"
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
 )
 
 func TestCurrentGetRequest(t *testing.T) {
@@ -46,4 +53,156 @@ func TestCurrentGetRequest(t *testing.T) {
 			assert.Equal(t, tc.neighbours, neighbours)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestCurrentGetHandler(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2022, Percentage: 72.1},
+		{Country: "Denmark", CountryCode: "DNK", Year: 2021, Percentage: 35.2},
+	}))
+
+	rr := httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	entries := []structures.CurrentEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 2)
+	assert.Equal(t, structures.CurrentEntry{DataEntry: structures.DataEntry{Country: "Denmark", CountryCode: "DNK", Year: 2021, Percentage: 35.2},
+		Staleness: structures.Staleness{LatestYear: 2022, YearsBehind: 1, Stale: true}}, entries[1])
+
+	//only the countries with data for the latest year in the data
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"?latest=global", nil))
+	entries = []structures.CurrentEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "NOR", entries[0].CountryCode)
+
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"dnk?latest=global", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"?latest=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestCurrentGetHandlerNeighbours(t *testing.T) {
+	defaultResolver, defaultData := countries.Default(), functions.CurrentData()
+	defer func() {
+		countries.SetDefault(defaultResolver)
+		functions.SetData(defaultData)
+	}()
+	countries.SetDefault(countries.New([]structures.Country{
+		{Alpha3: "NOR", Borders: []string{"SWE", "FIN"}, Name: structures.CountryName{Common: "Norway"}},
+		{Alpha3: "SWE", Borders: []string{"NOR", "FIN"}, Name: structures.CountryName{Common: "Sweden"}},
+		{Alpha3: "FIN", Borders: []string{"NOR", "SWE"}, Name: structures.CountryName{Common: "Finland"}},
+	}))
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9},
+	}))
+
+	//the borders come from the local country data, and Finland isn't in the energy data
+	rr := httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"nor?neighbours=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	neighbourEntries := []structures.CurrentEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &neighbourEntries))
+	assert.Len(t, neighbourEntries, 2)
+	assert.Equal(t, 0, *neighbourEntries[0].Distance)
+	assert.Equal(t, "SWE", neighbourEntries[1].CountryCode)
+	assert.Equal(t, 1, *neighbourEntries[1].Distance)
+
+	//in an envelope the entries come with the warnings
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"nor?neighbours=true&envelope=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	response := struct {
+		Data struct {
+			Entries  []structures.CurrentEntry `json:"entries"`
+			Warnings []string                  `json:"warnings"`
+		} `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, neighbourEntries, response.Data.Entries)
+	assert.Equal(t, []string{}, response.Data.Warnings)
+
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"nor?neighbours=true&depth=0", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	//without neighbours the response is the list of entries
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"nor", nil))
+	entries := []structures.CurrentEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+	assert.Nil(t, entries[0].Distance)
+}
+
+func TestCurrentGetHandlerNeighboursFromAPI(t *testing.T) {
+	defaultResolver, defaultClient, defaultData := countries.Default(), countries.DefaultClient, functions.CurrentData()
+	defer func() {
+		countries.SetDefault(defaultResolver)
+		countries.DefaultClient = defaultClient
+		functions.SetData(defaultData)
+	}()
+	//Sweden is only known by the countries API
+	countries.SetDefault(countries.New([]structures.Country{
+		{Alpha3: "NOR", Borders: []string{"SWE", "FIN"}, Name: structures.CountryName{Common: "Norway"}},
+		{Alpha3: "FIN", Borders: []string{"NOR", "SWE"}, Name: structures.CountryName{Common: "Finland"}},
+	}))
+	server := countriestest.NewServer([]structures.Country{
+		{Alpha3: "SWE", CountryCode: "SE", Borders: []string{"NOR", "FIN"}, Name: structures.CountryName{Common: "Sweden"}},
+	})
+	defer server.Close()
+	client := countries.NewHTTPClient(server.BaseURL)
+	client.Retries = 0
+	breaker := countries.NewBreaker(client)
+	countries.DefaultClient = breaker
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9},
+		{Country: "Finland", CountryCode: "FIN", Year: 2021, Percentage: 47.2},
+		{Country: "Denmark", CountryCode: "DNK", Year: 2021, Percentage: 41.4},
+		{Country: "Europe", Year: 2021, Percentage: 22.5},
+	}))
+
+	rr := httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"swe?neighbours=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, server.Requests(), 1)
+
+	//while the API fails the breaker answers with the last good lookup
+	server.Fail(http.StatusBadGateway, http.StatusBadGateway)
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"swe?neighbours=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	entries := []structures.CurrentEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 3)
+	assert.Equal(t, int64(1), breaker.Stats().StaleServed)
+
+	//and a country it has no answer for is returned alone, with a warning about its neighbours
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"dnk?neighbours=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	response := struct {
+		Entries  []structures.CurrentEntry `json:"entries"`
+		Warnings []string                  `json:"warnings"`
+	}{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response.Entries, 1)
+	assert.Equal(t, "DNK", response.Entries[0].CountryCode)
+	assert.Len(t, response.Warnings, 1)
+	assert.Equal(t, 2, breaker.Stats().Failures)
+
+	//aggregates are never looked up
+	requests := len(server.Requests())
+	rr = httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"europe?neighbours=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, server.Requests(), requests)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"groupXX/structures"
@@ -50,6 +51,7 @@ You are now at root level, choose what you want to do:<br><br>`
 	_, err := fmt.Fprintf(w, "%v", output)
 
 	if err != nil {
-		http.Error(w, "Error when returning output", http.StatusInternalServerError)
+		//the response has already started, so the error can only be logged
+		log.Printf("Error when returning output: %v", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/storage"
+	"groupXX/structures"
+	"groupXX/webhooks"
+)
+
+func DeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	//GET shows the delivery log, POST replays the dead letters
+	case http.MethodGet:
+		DeliveriesGetRequest(w, r)
+	case http.MethodPost:
+		DeliveriesPostRequest(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet, http.MethodPost))
+		return
+	}
+}
+
+// retrieves the webhook id from /energy/v1/notifications/{id}/deliveries
+func deliveriesWebhookID(r *http.Request) string {
+	id := strings.TrimPrefix(r.URL.Path, structures.NOTIFICATIONS_PATH)
+	return strings.TrimSuffix(id, structures.DELIVERIES_SUFFIX)
+}
+
+//get request, returns every recorded attempt and the dead letters of the webhook
+func DeliveriesGetRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := deliveriesWebhookID(r)
+	ctx := context.Background()
+
+	//makes sure the webhook exists so unknown ids give 404 instead of an empty log
+	_, err := storage.DB.GetWebhook(ctx, id)
+	if err == structures.ErrWebhookNotFound {
+		functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_WEBHOOK_NOT_FOUND,
+			err.Error()))
+		return
+	} else if err != nil {
+		functions.WriteError(w, r, err)
+		return
+	}
+
+	deliveries, err := storage.DB.GetDeliveries(ctx, id)
+	if err != nil {
+		functions.WriteError(w, r, err)
+		return
+	}
+	deadLetters, err := storage.DB.GetDeadLetters(ctx, id)
+	if err != nil {
+		functions.WriteError(w, r, err)
+		return
+	}
+
+	functions.PrintData(w, structures.DeliveryLog{Deliveries: deliveries, DeadLetters: deadLetters})
+}
+
+//post request, queues the dead letters of the webhook for delivery again
+func DeliveriesPostRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := deliveriesWebhookID(r)
+	replayed, err := webhooks.Default.Replay(context.Background(), id)
+	if err == structures.ErrWebhookNotFound {
+		functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_WEBHOOK_NOT_FOUND,
+			err.Error()))
+		return
+	} else if err != nil {
+		log.Printf("Error replaying dead letters: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+
+	//accepted since the deliveries happen in the background
+	w.WriteHeader(http.StatusAccepted)
+	functions.PrintData(w, map[string]int{"replayed": replayed})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func ForecastHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		ForecastGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// projects the percentage of a country until ?until= (2030 by default) with ?model=linear|holt, fitted to the
+// years from ?begin= to ?end=, every year by default
+func ForecastGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	queryParams := r.URL.Query()
+
+	country := strings.Trim(r.URL.Path[len(structures.FORECAST_PATH):], "/")
+	if country == "" {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_MISSING_PARAMETER,
+			"Missing country, expected "+structures.FORECAST_PATH+"{country}"))
+		return
+	}
+
+	until := structures.DEFAULTFORECASTYEAR
+	if untilStr := queryParams.Get("until"); untilStr != "" {
+		var err error
+		until, err = strconv.Atoi(untilStr)
+		if err != nil {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing until year string to integer"))
+			return
+		}
+	}
+
+	begin, end, ok := requestedYears(w, r)
+	if !ok {
+		return
+	}
+
+	model := strings.ToLower(queryParams.Get("model"))
+	if model == "" {
+		model = structures.FORECAST_LINEAR
+	}
+	if model != structures.FORECAST_LINEAR && model != structures.FORECAST_HOLT {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Error parsing model value, expected '"+structures.FORECAST_LINEAR+"' or '"+
+				structures.FORECAST_HOLT+"'"))
+		return
+	}
+
+	//?format= or the Accept header picks csv, ndjson or xlsx instead of JSON
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
+	data, err := functions.ReadCountryInfo(country, false, begin, end)
+	if err != nil {
+		log.Printf("Error reading CSV file: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+	//a known country without any of the years asked for is a bad range, not a country which isn't found
+	if data == nil {
+		NotFoundInYears(w, r, country)
+		return
+	}
+	if last := data[len(data)-1].Year; until-last > structures.MAXFORECASTYEARS {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Forecasts can go at most "+strconv.Itoa(structures.MAXFORECASTYEARS)+
+				" years past the last year in the data, "+strconv.Itoa(last)))
+		return
+	}
+
+	forecast, err := functions.Forecast(data, until, model)
+	if err != nil {
+		functions.WriteError(w, r, err)
+		return
+	}
+	countCalls([]string{country}, nil)
+	functions.PrintFormat(w, forecast, format)
+}
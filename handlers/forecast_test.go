@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TestForecastGetHandler(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2019, Percentage: 60},
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 65},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 70},
+	}))
+
+	rr := httptest.NewRecorder()
+	ForecastHandler(rr, httptest.NewRequest(http.MethodGet, structures.FORECAST_PATH+"nor", nil))
+	forecast := []structures.ForecastEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &forecast))
+	//2019 to 2030
+	assert.Len(t, forecast, 12)
+	assert.False(t, forecast[2].Projected)
+	assert.True(t, forecast[3].Projected)
+	assert.Equal(t, 2022, forecast[3].Year)
+	assert.InDelta(t, 75, forecast[3].Percentage, 1e-9)
+
+	rr = httptest.NewRecorder()
+	ForecastHandler(rr, httptest.NewRequest(http.MethodGet, structures.FORECAST_PATH+"nor?until=2023&model=holt", nil))
+	forecast = []structures.ForecastEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &forecast))
+	assert.Len(t, forecast, 5)
+
+	//a line per year with ndjson
+	rr = httptest.NewRecorder()
+	ForecastHandler(rr, httptest.NewRequest(http.MethodGet, structures.FORECAST_PATH+"nor?until=2023&format=ndjson", nil))
+	assert.Equal(t, structures.NDJSON_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+	assert.Len(t, strings.Split(strings.TrimSpace(rr.Body.String()), "\n"), 5)
+
+	//a range without any years of a known country too
+	for _, query := range []string{"?until=later", "?model=arima", "?until=2200", "?begin=2020", "?until=2020", "?end=2000"} {
+		rr = httptest.NewRecorder()
+		ForecastHandler(rr, httptest.NewRequest(http.MethodGet, structures.FORECAST_PATH+"nor"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+
+	rr = httptest.NewRecorder()
+	ForecastHandler(rr, httptest.NewRequest(http.MethodGet, structures.FORECAST_PATH+"nor?begin=2025", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), structures.ERR_INSUFFICIENT_DATA)
+
+	rr = httptest.NewRecorder()
+	ForecastHandler(rr, httptest.NewRequest(http.MethodGet, structures.FORECAST_PATH+"atlantis", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -1,15 +1,14 @@
 package handlers
 
 import (
-	"fmt"
 	"log"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
 
-	"groupXX/firebase"
 	"groupXX/functions"
+	"groupXX/regions"
 	"groupXX/structures"
 )
 
@@ -19,28 +18,25 @@ func HistoryHandler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		HistoryGetHandler(w, r)
 	default:
-		http.Error(w, "REST Method '"+r.Method+"' not supported. Currently only '"+http.MethodGet+
-			"' are supported.", http.StatusNotImplemented)
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
 		return
 	}
 }
 
+//parses the country, years and sorting of the request, writes the error to the user and returns it if they can't be parsed
 func HistoryGetRequest(w http.ResponseWriter, r *http.Request) (country string, begin int, end int, sorting bool, err error) {
 	w.Header().Add("content-type", "application/json")
 
 	//sets the basepath so we can work on top of that
 	basePath := "/energy/v1/renewables/history/"
 	if !strings.HasPrefix(r.URL.Path, basePath) {
-		http.Error(w, "Invalid URL path", http.StatusBadRequest)
+		err = functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER, "Invalid URL path")
+		functions.WriteError(w, r, err)
 		return
 	}
 
 	//extract the country value from the path
 	country = strings.TrimPrefix(r.URL.Path, basePath)
-	err = firebase.UpdateCalls(country)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
 
 	//extract the query parameters
 	queryParams := r.URL.Query()
@@ -56,7 +52,8 @@ func HistoryGetRequest(w http.ResponseWriter, r *http.Request) (country string,
 	if beginStr != "" {
 		begin, err = strconv.Atoi(beginStr)
 		if err != nil {
-			http.Error(w, "Error parsing begin year string to integer", http.StatusBadRequest)
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing begin year string to integer"))
 			return
 		}
 	}
@@ -64,7 +61,8 @@ func HistoryGetRequest(w http.ResponseWriter, r *http.Request) (country string,
 	if endStr != "" {
 		end, err = strconv.Atoi(endStr)
 		if err != nil {
-			http.Error(w, "Error parsing end year string to integer", http.StatusBadRequest)
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing end year string to integer"))
 			return
 		}
 	}
@@ -74,7 +72,8 @@ func HistoryGetRequest(w http.ResponseWriter, r *http.Request) (country string,
 	if sortingByValueStr != "" {
 		sorting, err = strconv.ParseBool(sortingByValueStr)
 		if err != nil {
-			http.Error(w, "Error parsing sorting value string to bool", http.StatusBadRequest)
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing sorting value string to bool"))
 			return
 		}
 	}
@@ -87,37 +86,122 @@ func HistoryGetHandler(w http.ResponseWriter, r *http.Request) {
 
 	countryName, begin, end, sorting, err := HistoryGetRequest(w,r)
 	if err != nil{
-		log.Printf("Error parsing URL")
+		return
 	}
 
-	var data []structures.DataEntry
+	//?metric= shows another metric in the data than the renewables percentage
+	_, metric, ok := requestedMetric(w, r)
+	if !ok {
+		return
+	}
+	aggregates, ok := includeAggregates(w, r)
+	if !ok {
+		return
+	}
+
+	//?neighbours=true adds the countries within ?depth= land borders of the country
+	neighbours := false
+	if neighboursStr := r.URL.Query().Get("neighbours"); neighboursStr != "" {
+		neighbours, err = strconv.ParseBool(neighboursStr)
+		if err != nil {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing neighbours value from string to bool: "+err.Error()))
+			return
+		}
+	}
+	depth, ok := requestedDepth(w, r)
+	if !ok {
+		return
+	}
+	//?page= and ?limit= split long lists like every country, and ?envelope=true adds what the list is
+	page, limit, ok := requestedPage(w, r)
+	if !ok {
+		return
+	}
+	envelope, ok := requestedEnvelope(w, r)
+	if !ok {
+		return
+	}
+	//?format= or the Accept header picks csv, ndjson or xlsx instead of JSON
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
 
 	//handle the begin and end specifications, turned them into pointers to deal with their absence
-	if begin == 0 && end == 0 {
-		data, err = functions.ReadCountryInfo(w, countryName, false, nil, nil)
-	} else if begin == 0 && end != 0 {
-		data, err = functions.ReadCountryInfo(w, countryName, false, nil, &end)
-	} else if begin != 0 && end == 0 {
-		data, err = functions.ReadCountryInfo(w, countryName, false, &begin, nil)
-	} else if begin != 0 && end != 0 {
-		data, err = functions.ReadCountryInfo(w, countryName, false, &begin, &end)
+	var beginPtr, endPtr *int
+	if begin != 0 {
+		beginPtr = &begin
+	}
+	if end != 0 {
+		endPtr = &end
+	}
+	readHistory := func(country string) ([]structures.DataEntry, error) {
+		return functions.ReadMetricInfo(metric, country, false, beginPtr, endPtr)
+	}
+
+	data, err := readHistory(countryName)
+	if err != nil {
+		log.Printf("Error reading CSV file: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+	//?aggregates=false lists only countries, without regions like Europe
+	if countryName == "" && !aggregates {
+		data = regions.ExcludeAggregates(data)
 	}
 
 	//based on the potential calls of the ReadCountryInfo, checks if data is returned (found)
 	if data == nil {
-		fmt.Fprint(w, "No return for the given search found")
-	} else {
+		NotFound(w, r, countryName)
+		return
+	}
+
+	var distances map[string]int
+	var warnings []string
+	if neighbours && countryName != "" {
+		data, distances, warnings, err = withNeighbours(r, data, depth, readHistory)
 		if err != nil {
-			log.Printf("Error reading CSV file: %v", err)
+			log.Printf("Error retrieving neighbours: %v", err)
+			functions.WriteError(w, r, err)
+			return
 		}
+	}
+	countCalls([]string{countryName}, distances)
+
+	//sort the data based on Percentage if sorting is true
+	if sorting {
+		sort.Sort(ByPercentage(data))
+	}
+
+	data, meta := paginate(w, r, data, page, limit)
 
-		//sort the data based on Percentage if sorting is true
-		if sorting {
-			sort.Sort(ByPercentage(data))
+	if distances == nil {
+		if metric == structures.DEFAULTMETRIC {
+			printList(w, data, meta, envelope, format)
+		} else {
+			printList(w, functions.ToMetricEntries(data, metric), meta, envelope, format)
 		}
+		return
+	}
 
-		functions.PrintData(w, data)
+	//each entry tells how many land borders its country is from the country asked for
+	var entries interface{}
+	if metric == structures.DEFAULTMETRIC {
+		annotated := make([]structures.NeighbourEntry, len(data))
+		for i, entry := range data {
+			annotated[i] = structures.NeighbourEntry{DataEntry: entry, Distance: distances[countryKey(entry)]}
+		}
+		entries = annotated
+	} else {
+		annotated := functions.ToMetricEntries(data, metric)
+		for i := range annotated {
+			distance := distances[countryKey(data[i])]
+			annotated[i].Distance = &distance
+		}
+		entries = annotated
 	}
+	printList(w, neighbourResponse(entries, warnings, envelope), meta, envelope, format)
 }
 
 //list of DataEntry structs to store in a structured way
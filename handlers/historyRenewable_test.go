@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"testing"
 	"reflect"
 	"sort"
@@ -9,6 +10,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"groupXX/countries"
+	"groupXX/dataset"
+	"groupXX/functions"
 	"groupXX/structures"
 
 )
@@ -95,3 +99,123 @@ func TestHistoryGetRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestHistoryGetHandlerMetric(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.FromTable(&dataset.Table{
+		Rows: []structures.DataEntry{
+			{Country: "Norway", CountryCode: "NOR", Year: 2020},
+			{Country: "Norway", CountryCode: "NOR", Year: 2021},
+		},
+		Columns: map[string][]float64{
+			structures.DEFAULTMETRIC: {70.9, 71.5},
+			"energy_per_capita":      {101000, 99000},
+		},
+	}))
+
+	rr := httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?metric=energy_per_capita&begin=2021", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	entries := []structures.MetricEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Equal(t, []structures.MetricEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021, Metric: "energy_per_capita", Value: 99000}}, entries)
+
+	//without a metric the response is unchanged
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?begin=2021", nil))
+	data := []structures.DataEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &data))
+	assert.Equal(t, []structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.5}}, data)
+
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?metric=wind", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	MetricsHandler(rr, httptest.NewRequest(http.MethodGet, structures.METRICS_PATH, nil))
+	metrics := []string{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &metrics))
+	assert.Equal(t, []string{"energy_per_capita", structures.DEFAULTMETRIC}, metrics)
+}
+
+func TestHistoryGetHandlerNeighbours(t *testing.T) {
+	defaultResolver, defaultData := countries.Default(), functions.CurrentData()
+	defer func() {
+		countries.SetDefault(defaultResolver)
+		functions.SetData(defaultData)
+	}()
+	countries.SetDefault(countries.New([]structures.Country{
+		{Alpha3: "NOR", Borders: []string{"SWE"}, Name: structures.CountryName{Common: "Norway"}},
+		{Alpha3: "SWE", Borders: []string{"NOR", "FIN"}, Name: structures.CountryName{Common: "Sweden"}},
+		{Alpha3: "FIN", Borders: []string{"SWE"}, Name: structures.CountryName{Common: "Finland"}},
+	}))
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 71},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 72},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 51},
+		{Country: "Finland", CountryCode: "FIN", Year: 2021, Percentage: 35},
+	}))
+
+	//Finland is two land borders from Norway, through Sweden
+	rr := httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?neighbours=true&depth=2", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	entries := []structures.NeighbourEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Equal(t, []structures.NeighbourEntry{
+		{DataEntry: structures.DataEntry{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 71}, Distance: 0},
+		{DataEntry: structures.DataEntry{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 72}, Distance: 0},
+		{DataEntry: structures.DataEntry{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 51}, Distance: 1},
+		{DataEntry: structures.DataEntry{Country: "Finland", CountryCode: "FIN", Year: 2021, Percentage: 35}, Distance: 2},
+	}, entries)
+
+	//only one land border by default, and only the years asked for
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?neighbours=true&begin=2021", nil))
+	entries = nil
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 2)
+
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?neighbours=maybe", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHistoryGetHandlerErrors(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6},
+	}))
+
+	//a parameter which can't be parsed is one problem, and the handler stops there
+	rr := httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?begin=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, structures.PROBLEM_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+	problem := structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, structures.ERR_INVALID_PARAMETER, problem.Code)
+
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?metric=coal", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	problem = structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, structures.ERR_UNKNOWN_METRIC, problem.Code)
+
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodPut, structures.RENEWABLEHISTORY_PATH, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, http.MethodGet, rr.Header().Get("Allow"))
+	problem = structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, structures.ERR_METHOD_NOT_SUPPORTED, problem.Code)
+
+	//without data the service is unavailable instead of not finding the country
+	functions.SetData(nil)
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
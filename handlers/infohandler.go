@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"github.com/gomarkdown/markdown"
 	"io/ioutil"
 	
+	"groupXX/functions"
 	"groupXX/structures"
 )
 
@@ -15,8 +17,7 @@ func InfoHandler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		InfoGetHandler(w, r)
 	default:
-		http.Error(w, "This service only offers the <a href=\"/diag\">/diag endpoint</a> that shows comprehensive HTTP "+
-			"information for any received request. Please redirect any request to that endpoint.", http.StatusInternalServerError)
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
 		return
 	}
 }
@@ -28,7 +29,7 @@ func InfoGetHandler(w http.ResponseWriter, r *http.Request) {
 	//read the contents of your Markdown file
 	mdFileContent, err := ioutil.ReadFile("./README.md")
 	if err != nil {
-		http.Error(w, "Error when reading markdown file", http.StatusInternalServerError)
+		functions.WriteError(w, r, fmt.Errorf("Error when reading markdown file: %v", err))
 		return
 	}
 
@@ -66,6 +67,7 @@ func InfoGetHandler(w http.ResponseWriter, r *http.Request) {
 	_, err = fmt.Fprintf(w, "%v", output)
 
 	if err != nil {
-		http.Error(w, "Error when returning output", http.StatusInternalServerError)
+		//the response has already started, so the error can only be logged
+		log.Printf("Error when returning output: %v", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		functions.PrintData(w, functions.CurrentData().Metrics())
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// reads ?metric= and returns the dataset of it, writes a 400 and returns false if the data doesn't have the metric,
+// or a 503 if no data is loaded
+func requestedMetric(w http.ResponseWriter, r *http.Request) (*dataset.Dataset, string, bool) {
+	metric := strings.ToLower(r.URL.Query().Get("metric"))
+	if metric == "" {
+		metric = structures.DEFAULTMETRIC
+	}
+	if functions.CurrentData() == nil {
+		functions.WriteError(w, r, functions.NewError(http.StatusServiceUnavailable, structures.ERR_UNAVAILABLE, "No data loaded"))
+		return nil, "", false
+	}
+	data, ok := functions.CurrentData().Metric(metric)
+	if !ok {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_UNKNOWN_METRIC,
+			"Unknown metric '"+metric+"', the loaded metrics are listed at "+structures.METRICS_PATH))
+		return nil, "", false
+	}
+	return data, metric, true
+}
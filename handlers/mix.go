@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func MixHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		MixGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// returns the energy mix of a country, ?year= is either one year or a range like 2010-2020 (or ?begin= and ?end=),
+// the latest year by default
+func MixGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	country := strings.Trim(r.URL.Path[len(structures.MIX_PATH):], "/")
+	if country == "" {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_MISSING_PARAMETER,
+			"Missing country, expected "+structures.MIX_PATH+"{country}"))
+		return
+	}
+
+	begin, end, ok := requestedYears(w, r)
+	if !ok {
+		return
+	}
+
+	//?format= or the Accept header picks csv, ndjson or xlsx instead of JSON
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
+
+	mixes, err := functions.EnergyMix(country, begin, end)
+	if err != nil {
+		log.Printf("Error reading energy mix: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+	if mixes == nil {
+		NotFound(w, r, country)
+		return
+	}
+	countCalls([]string{country}, nil)
+	functions.PrintFormat(w, mixes, format)
+}
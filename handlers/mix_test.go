@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TestMixGetHandlerBadRequest(t *testing.T) {
+	rr := httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH, nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH+"norway?year=recent", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestMixGetHandler(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	data, err := functions.LoadData("./testMixData.csv")
+	assert.NoError(t, err)
+	functions.SetData(data)
+
+	rr := httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH+"norway", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mixes := []structures.Mix{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &mixes))
+	assert.Equal(t, []structures.Mix{{Country: "Norway", CountryCode: "NOR", Year: 2021, Sources: map[string]float64{
+		"hydro": 64.2, "wind": 6.1, "solar": 0.02, "biofuel": 0.73, "other_renewables": 0.37, "nuclear": 0, "fossil": 28.5}}}, mixes)
+
+	rr = httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH+"nor?year=2010-2021", nil))
+	mixes = []structures.Mix{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &mixes))
+	assert.Len(t, mixes, 2)
+
+	//a known country without shares for the year is an empty list
+	rr = httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH+"sweden?year=2020", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "[]\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH+"atlantis", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH+"sweden?format=csv", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "Entity,Code,Year,hydro_share_energy,wind_share_energy,solar_share_energy,biofuel_share_energy,"+
+		"other_renewables_share_energy,nuclear_share_energy,fossil_share_energy\nSweden,SWE,2021,28.1,10.6,0.6,10.9,0.7,30.4,18.7\n",
+		rr.Body.String())
+
+	//the renewables file has no sources, which isn't the country's fault
+	functions.SetData(dataset.New([]structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.5}}))
+	rr = httptest.NewRecorder()
+	MixHandler(rr, httptest.NewRequest(http.MethodGet, structures.MIX_PATH+"norway", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	problem := structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, structures.ERR_UNAVAILABLE, problem.Code)
+}
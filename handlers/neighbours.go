@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"groupXX/functions"
+	"groupXX/regions"
+	"groupXX/structures"
+)
+
+// reads ?depth=, how many land borders away neighbours are included, 1 by default.
+// Writes a 400 and returns false if it isn't a number from 1 to MAXNEIGHBOURDEPTH
+func requestedDepth(w http.ResponseWriter, r *http.Request) (int, bool) {
+	depthStr := r.URL.Query().Get("depth")
+	if depthStr == "" {
+		return 1, true
+	}
+	depth, err := strconv.Atoi(depthStr)
+	if err != nil || depth < 1 || depth > structures.MAXNEIGHBOURDEPTH {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Error parsing depth value, expected a number from 1 to "+strconv.Itoa(structures.MAXNEIGHBOURDEPTH)))
+		return 0, false
+	}
+	return depth, true
+}
+
+// adds the entries of every country within depth land borders of the country of the data, read with read.
+// Returns the entries, the distance of each country by countryKey and warnings for the neighbours which
+// couldn't be read. Neighbours without data are left out
+func withNeighbours(r *http.Request, data []structures.DataEntry, depth int,
+	read func(country string) ([]structures.DataEntry, error)) ([]structures.DataEntry, map[string]int, []string, error) {
+	center := structures.DataEntry{Country: data[0].Country, CountryCode: data[0].CountryCode}
+	distances := map[string]int{countryKey(center): 0}
+
+	//aggregates like continents have no ISO code and no borders, so they are never looked up in the countries API
+	if regions.IsAggregate(center) {
+		return data, distances, []string{}, nil
+	}
+	//the borders are looked up and the neighbours read at the same time, and the request gives up on them when
+	//they take too long
+	ctx, cancel := context.WithTimeout(r.Context(), structures.NEIGHBOURTIMEOUT)
+	defer cancel()
+	neighbours, lookupWarnings, err := functions.NeighboursWithin(ctx, center.CountryCode, depth)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	neighbourData, warnings := functions.ReadNeighbours(ctx, neighbours, read)
+
+	for i, entries := range neighbourData {
+		//in cases where the neighbour country doesn't exist in the csv file
+		if len(entries) == 0 {
+			continue
+		}
+		distances[countryKey(entries[0])] = neighbours[i].Distance
+		data = append(data, entries...)
+	}
+	return data, distances, append(lookupWarnings, warnings...), nil
+}
+
+// body of a response with neighbours, the entries like without neighbours unless some neighbours couldn't be
+// read or the response is enveloped, then the entries and the warnings together
+func neighbourResponse(entries interface{}, warnings []string, envelope bool) interface{} {
+	if len(warnings) == 0 && !envelope {
+		return entries
+	}
+	return structures.NeighbourResponse{Entries: entries, Warnings: warnings}
+}
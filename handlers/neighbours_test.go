@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/structures"
+)
+
+func TestNeighbourResponse(t *testing.T) {
+	entries := []structures.NeighbourEntry{{DataEntry: structures.DataEntry{CountryCode: "NOR"}}}
+
+	//the entries alone when every neighbour was read, like without neighbours
+	assert.Equal(t, entries, neighbourResponse(entries, []string{}, false))
+
+	//wrapped with the warnings when a neighbour couldn't be read, or in an envelope
+	warnings := []string{"Error reading neighbour SWE: timeout"}
+	assert.Equal(t, structures.NeighbourResponse{Entries: entries, Warnings: warnings}, neighbourResponse(entries, warnings, false))
+	assert.Equal(t, structures.NeighbourResponse{Entries: entries, Warnings: []string{}}, neighbourResponse(entries, []string{}, true))
+}
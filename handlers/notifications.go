@@ -4,24 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
-	"groupXX/firebase"
-	"groupXX/structures"
 	"groupXX/functions"
+	"groupXX/storage"
+	"groupXX/structures"
+	"groupXX/webhooks"
 )
 
 func NotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	//the deliveries of a webhook are a resource of their own
+	if strings.HasSuffix(r.URL.Path, structures.DELIVERIES_SUFFIX) {
+		DeliveriesHandler(w, r)
+		return
+	}
+
 	switch r.Method {
 	//provides different methods for the notification handler
 	case http.MethodGet:
 		NotificationsGetRequest(w, r)
 	case http.MethodPost:
 		NotificationsPostRequest(w, r)
+	case http.MethodPatch:
+		NotificationsPatchRequest(w, r)
 	case http.MethodDelete:
 		NotificationsDeleteRequest(w, r)
 	default:
-		http.Error(w, "REST Method '"+r.Method+"' not supported. Currently only '"+http.MethodGet+","+
-			http.MethodDelete+" and "+http.MethodPost+"' are supported.", http.StatusNotImplemented)
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet, http.MethodPatch,
+			http.MethodDelete, http.MethodPost))
 		return
 	}
 }
@@ -34,20 +44,35 @@ func NotificationsGetRequest(w http.ResponseWriter, r *http.Request) {
 	//retrieves the user inputted id
 	id := r.URL.Path[len(basePath):]
 
-	//opens firebase project and creates a client
 	ctx := context.Background()
-	client, err := firebase.CreateFirestoreClient(ctx)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	//without an id every registered webhook is returned
+	if id == "" {
+		webhooks, err := storage.DB.GetAllWebhooks(ctx)
+		if err != nil {
+			functions.WriteError(w, r, err)
+			return
+		}
+		for i := range webhooks {
+			webhooks[i].Webhook.Secret = ""
+		}
+		functions.PrintData(w, webhooks)
+		return
 	}
 
 	//gets the webhook based on the id
-	wh, err := firebase.GetWebhook(ctx, client, id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	wh, err := storage.DB.GetWebhook(ctx, id)
+	if err == structures.ErrWebhookNotFound {
+		functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_WEBHOOK_NOT_FOUND,
+			err.Error()))
+		return
+	} else if err != nil {
+		functions.WriteError(w, r, err)
+		return
 	}
 
-	//and returns it
+	//and returns it, without the secret
+	wh.Secret = ""
 	functions.PrintData(w, wh)
 }
 
@@ -59,21 +84,43 @@ func NotificationsPostRequest(w http.ResponseWriter, r *http.Request) {
 	wh := structures.Webhook{}
 	err := json.NewDecoder(r.Body).Decode(&wh)
 	if err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Bad request"))
+		return
 	}
 
-	//opens the firestore project for uses and Stores a webhook there with the given id
-	ctx := context.Background()
-	client, err := firebase.CreateFirestoreClient(ctx)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	//checks the event specific fields
+	switch wh.Event {
+	case "", structures.EVENT_CALLS:
+		if wh.Calls <= 0 {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Bad request, calls has to be a positive number"))
+			return
+		}
+	case structures.EVENT_THRESHOLD:
+		if wh.Direction != structures.DIRECTION_ABOVE && wh.Direction != structures.DIRECTION_BELOW {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Bad request, direction has to be '"+structures.DIRECTION_ABOVE+"' or '"+structures.DIRECTION_BELOW+"'"))
+			return
+		}
+		//the current side of the threshold, so it only fires when the data changes
+		wh.LastState = webhooks.ThresholdState(wh, functions.CurrentData().All())
+	default:
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Bad request, event has to be '"+structures.EVENT_CALLS+"' or '"+structures.EVENT_THRESHOLD+"'"))
+		return
 	}
-	id, err := firebase.StoreWebhooks(ctx, client, wh)
+
+	//stores the webhook in the configured storage which gives it an id
+	ctx := context.Background()
+	id, err := storage.DB.StoreWebhook(ctx, wh)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		functions.WriteError(w, r, err)
+		return
 	}
 
-	//registers with the registration struct
+	//registers with the registration struct, the secret is only known by the user who registered it
+	wh.Secret = ""
 	resp := structures.WebhookRegistration{
 		ID:      id,
 		Webhook: wh,
@@ -82,21 +129,54 @@ func NotificationsPostRequest(w http.ResponseWriter, r *http.Request) {
 	functions.PrintData(w, resp)
 }
 
-//delete request
-func NotificationsDeleteRequest(w http.ResponseWriter, r *http.Request) {
+//patch request, used to rotate the secret which signs the deliveries
+func NotificationsPatchRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
 	basePath := structures.NOTIFICATIONS_PATH
-	//user inputted id which they want to delete
 	id := r.URL.Path[len(basePath):]
 
+	patch := structures.WebhookPatch{}
+	err := json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil || patch.Secret == nil {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Bad request, expected a body like {\"secret\": \"<new secret>\"}"))
+		return
+	}
+
+	//an empty secret turns signing off, and only the secret is written so a threshold check at the same time
+	//isn't undone
 	ctx := context.Background()
-	client, err := firebase.CreateFirestoreClient(ctx)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	err = storage.DB.SetWebhookSecret(ctx, id, *patch.Secret)
+	wh := structures.Webhook{}
+	if err == nil {
+		wh, err = storage.DB.GetWebhook(ctx, id)
+	}
+	if err == structures.ErrWebhookNotFound {
+		functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_WEBHOOK_NOT_FOUND,
+			err.Error()))
+		return
+	} else if err != nil {
+		functions.WriteError(w, r, err)
+		return
 	}
 
+	wh.Secret = ""
+	functions.PrintData(w, structures.WebhookRegistration{ID: id, Webhook: wh})
+}
+
+//delete request
+func NotificationsDeleteRequest(w http.ResponseWriter, r *http.Request) {
+	basePath := structures.NOTIFICATIONS_PATH
+	//user inputted id which they want to delete
+	id := r.URL.Path[len(basePath):]
+
 	//deletes webhook based on id
-	err = firebase.DeleteWebhook(ctx, client, id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	err := storage.DB.DeleteWebhook(context.Background(), id)
+	if err == structures.ErrWebhookNotFound {
+		functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_WEBHOOK_NOT_FOUND,
+			err.Error()))
+	} else if err != nil {
+		functions.WriteError(w, r, err)
 	}
 }
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+func TestNotificationsSecret(t *testing.T) {
+	storage.DB = storage.NewMemoryStore()
+
+	//registers a webhook with a secret
+	body := `{"url": "http://localhost:8081/client/", "country": "NOR", "calls": 5, "secret": "first"}`
+	rr := httptest.NewRecorder()
+	NotificationsHandler(rr, httptest.NewRequest(http.MethodPost, structures.NOTIFICATIONS_PATH, strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "first")
+
+	registration := structures.WebhookRegistration{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &registration))
+
+	//the secret is stored but never shown
+	rr = httptest.NewRecorder()
+	NotificationsHandler(rr, httptest.NewRequest(http.MethodGet, structures.NOTIFICATIONS_PATH+registration.ID, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "first")
+
+	//rotates the secret
+	rr = httptest.NewRecorder()
+	NotificationsHandler(rr, httptest.NewRequest(http.MethodPatch, structures.NOTIFICATIONS_PATH+registration.ID,
+		strings.NewReader(`{"secret": "second"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "second")
+
+	stored, err := storage.DB.GetWebhook(context.Background(), registration.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", stored.Secret)
+
+	//unknown ids and bodies without a secret are rejected
+	rr = httptest.NewRecorder()
+	NotificationsHandler(rr, httptest.NewRequest(http.MethodPatch, structures.NOTIFICATIONS_PATH+"unknown",
+		strings.NewReader(`{"secret": "third"}`)))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	NotificationsHandler(rr, httptest.NewRequest(http.MethodPatch, structures.NOTIFICATIONS_PATH+registration.ID,
+		strings.NewReader(`{"url": "http://elsewhere"}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+// reads ?page= and ?limit=, the page from 1 and how many entries it has. The page is 0 if neither is given, then
+// the list isn't paginated. Writes a 400 and returns false if they aren't positive numbers or the limit is too high
+func requestedPage(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	pageStr, limitStr := r.URL.Query().Get("page"), r.URL.Query().Get("limit")
+	if pageStr == "" && limitStr == "" {
+		return 0, 0, true
+	}
+	page, limit := 1, structures.DEFAULTPAGELIMIT
+	var err error
+	if pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing page value, expected a positive number"))
+			return 0, 0, false
+		}
+	}
+	if limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > structures.MAXPAGELIMIT {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing limit value, expected a number from 1 to "+strconv.Itoa(structures.MAXPAGELIMIT)))
+			return 0, 0, false
+		}
+	}
+	return page, limit, true
+}
+
+// reads ?envelope=, false by default so the response is the bare list it has always been.
+// Writes a 400 and returns false if it isn't a bool
+func requestedEnvelope(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	envelopeStr := r.URL.Query().Get("envelope")
+	if envelopeStr == "" {
+		return false, true
+	}
+	envelope, err := strconv.ParseBool(envelopeStr)
+	if err != nil {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Error parsing envelope value from string to bool: "+err.Error()))
+		return false, false
+	}
+	return envelope, true
+}
+
+// returns the entries of the page of data and the metadata of the response, and sets the Link header to the
+// first, previous, next and last page. All of data is returned if page is 0
+func paginate(w http.ResponseWriter, r *http.Request, data []structures.DataEntry,
+	page int, limit int) ([]structures.DataEntry, structures.Meta) {
+	if page == 0 {
+		return data, functions.NewMeta(len(data), len(data))
+	}
+	start, end, pages := functions.Paginate(len(data), page, limit)
+
+	links := []string{pageLink(r, 1, limit, "first")}
+	if page > 1 {
+		previous := page - 1
+		if previous > pages {
+			previous = pages
+		}
+		links = append(links, pageLink(r, previous, limit, "prev"))
+	}
+	if page < pages {
+		links = append(links, pageLink(r, page+1, limit, "next"))
+	}
+	links = append(links, pageLink(r, pages, limit, "last"))
+	w.Header().Set("Link", strings.Join(links, ", "))
+
+	meta := functions.NewMeta(end-start, len(data))
+	meta.Page, meta.Limit, meta.Pages = page, limit, pages
+	return data[start:end], meta
+}
+
+// link to another page of the same request, as a Link header value with the relation to the page
+func pageLink(r *http.Request, page int, limit int, rel string) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
+	return "<" + r.URL.Path + "?" + query.Encode() + ">; rel=\"" + rel + "\""
+}
+
+// reads ?format= or else the Accept header, JSON by default. Writes a 400 for an unknown format or a 406 if none of
+// the accepted types are available, and returns false
+func requestedFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	//the response depends on the Accept header, so caches have to keep them apart
+	w.Header().Add("Vary", "Accept")
+	format, err := functions.NegotiateFormat(r.URL.Query().Get("format"), r.Header.Get("Accept"))
+	if err != nil {
+		functions.WriteError(w, r, err)
+		return "", false
+	}
+	return format, true
+}
+
+// prints the response of a list in the format, in an envelope with the metadata if it was asked for.
+// The envelope is JSON, so the other formats only have the entries
+func printList(w http.ResponseWriter, response interface{}, meta structures.Meta, envelope bool, format string) {
+	if format != structures.FORMAT_JSON {
+		functions.PrintFormat(w, response, format)
+		return
+	}
+	if envelope {
+		functions.PrintData(w, structures.Envelope{Data: response, Meta: meta})
+		return
+	}
+	functions.PrintData(w, response)
+}
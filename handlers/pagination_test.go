@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TestHistoryGetHandlerPagination(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Denmark", CountryCode: "DNK", Year: 2020, Percentage: 33.1},
+		{Country: "Denmark", CountryCode: "DNK", Year: 2021, Percentage: 35.2},
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 70.9},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9},
+	}))
+
+	//without any of the parameters the response is the bare list of every entry
+	rr := httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH, nil))
+	entries := []structures.DataEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 5)
+	assert.Empty(t, rr.Header().Get("Link"))
+
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"?page=2&limit=2&envelope=true", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	response := struct {
+		Data []structures.DataEntry `json:"data"`
+		Meta structures.Meta        `json:"meta"`
+	}{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, entries[2:4], response.Data)
+	assert.Equal(t, 2, response.Meta.Count)
+	assert.Equal(t, 5, response.Meta.Total)
+	assert.Equal(t, 3, response.Meta.Pages)
+	assert.Equal(t, functions.CurrentData().Version(), response.Meta.Version)
+	assert.Equal(t, `</energy/v1/renewables/history/?envelope=true&limit=2&page=1>; rel="first", `+
+		`</energy/v1/renewables/history/?envelope=true&limit=2&page=1>; rel="prev", `+
+		`</energy/v1/renewables/history/?envelope=true&limit=2&page=3>; rel="next", `+
+		`</energy/v1/renewables/history/?envelope=true&limit=2&page=3>; rel="last"`, rr.Header().Get("Link"))
+
+	//a page is a bare list as well without the envelope
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"?page=3&limit=2", nil))
+	entries = []structures.DataEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Equal(t, []structures.DataEntry{{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9}}, entries)
+	assert.NotContains(t, rr.Header().Get("Link"), `rel="next"`)
+
+	for _, query := range []string{"?page=0", "?limit=abc", "?limit=100000", "?envelope=maybe"} {
+		rr = httptest.NewRecorder()
+		HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestCurrentGetHandlerEnvelope(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2022, Percentage: 72.1},
+		{Country: "Denmark", CountryCode: "DNK", Year: 2021, Percentage: 35.2},
+	}))
+
+	rr := httptest.NewRecorder()
+	CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"?envelope=true", nil))
+	response := struct {
+		Data []structures.CurrentEntry `json:"data"`
+		Meta structures.Meta           `json:"meta"`
+	}{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, 2, response.Meta.Count)
+	assert.Equal(t, 2, response.Meta.Total)
+	//not paginated, so there are no page fields
+	assert.Zero(t, response.Meta.Pages)
+	assert.NotContains(t, rr.Body.String(), `"page"`)
+}
+
+func TestHistoryGetHandlerFormats(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 70.9},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6},
+	}))
+
+	rr := httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?format=csv", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Entity,Code,Year,"+structures.RENEWABLESHEADER+"\nNorway,NOR,2020,70.9\nNorway,NOR,2021,71.6\n", rr.Body.String())
+
+	//the Accept header is used without ?format=, and pages are lines as well
+	req := httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"?page=2&limit=1&envelope=true", nil)
+	req.Header.Set("Accept", structures.NDJSON_CONTENT_TYPE)
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, req)
+	assert.Equal(t, structures.NDJSON_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+	assert.Equal(t, `{"name":"Norway","isoCode":"NOR","year":2021,"percentage":71.6}`+"\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor?format=xlsx", nil))
+	assert.Equal(t, structures.XLSX_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "PK", rr.Body.String()[:2])
+
+	req = httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"nor", nil)
+	req.Header.Set("Accept", "image/png")
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, req)
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	assert.Equal(t, structures.PROBLEM_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+}
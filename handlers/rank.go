@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/regions"
+	"groupXX/structures"
+)
+
+func RankHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		RankGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// ranks the countries by their percentage for ?year=, the latest year by default. ?top= sets how many are
+// listed, ?order=asc lists the lowest first, ?region= only ranks its members and ?compare= sets the year the
+// rank change is against, the year before by default
+func RankGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	queryParams := r.URL.Query()
+
+	year := functions.CurrentData().LatestYear()
+	if yearStr := queryParams.Get("year"); yearStr != "" {
+		var err error
+		year, err = strconv.Atoi(yearStr)
+		if err != nil {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing year string to integer"))
+			return
+		}
+	}
+
+	compareYear := year - 1
+	if compareStr := queryParams.Get("compare"); compareStr != "" {
+		var err error
+		compareYear, err = strconv.Atoi(compareStr)
+		if err != nil {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing compare year string to integer"))
+			return
+		}
+	}
+
+	top := structures.DEFAULTTOP
+	if topStr := queryParams.Get("top"); topStr != "" {
+		var err error
+		top, err = strconv.Atoi(topStr)
+		if err != nil || top < 1 {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing top value, expected a positive number"))
+			return
+		}
+	}
+
+	order := strings.ToLower(queryParams.Get("order"))
+	if order == "" {
+		order = structures.ORDER_DESC
+	}
+	if order != structures.ORDER_DESC && order != structures.ORDER_ASC {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Error parsing order value, expected '"+structures.ORDER_DESC+"' or '"+structures.ORDER_ASC+"'"))
+		return
+	}
+
+	var members []string
+	if regionName := queryParams.Get("region"); regionName != "" {
+		region, ok := regions.Default.Get(regionName)
+		if !ok {
+			functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_REGION_NOT_FOUND,
+				"No region named '"+regionName+"'"))
+			return
+		}
+		members = region.Members
+	}
+
+	//?format= or the Accept header picks csv, ndjson or xlsx instead of JSON
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
+	ranked, err := functions.Rank(&year, &compareYear, members, order == structures.ORDER_ASC)
+	if err != nil {
+		log.Printf("Error ranking countries: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	functions.PrintFormat(w, ranked, format)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TestRankGetHandler(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 70},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2020, Percentage: 50},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 72},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 52},
+		{Country: "Finland", CountryCode: "FIN", Year: 2021, Percentage: 35},
+	}))
+
+	rr := httptest.NewRecorder()
+	RankHandler(rr, httptest.NewRequest(http.MethodGet, structures.RANK_PATH+"?top=2&order=asc", nil))
+	ranked := []structures.RankEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ranked))
+	assert.Len(t, ranked, 2)
+	assert.Equal(t, "FIN", ranked[0].CountryCode)
+	assert.Nil(t, ranked[0].RankChange)
+	assert.Equal(t, "SWE", ranked[1].CountryCode)
+	assert.Equal(t, 1, *ranked[1].PreviousRank)
+
+	for _, query := range []string{"?year=latest", "?compare=x", "?top=0", "?order=up"} {
+		rr = httptest.NewRecorder()
+		RankHandler(rr, httptest.NewRequest(http.MethodGet, structures.RANK_PATH+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+
+	rr = httptest.NewRecorder()
+	RankHandler(rr, httptest.NewRequest(http.MethodGet, structures.RANK_PATH+"?region=atlantis", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	RankHandler(rr, httptest.NewRequest(http.MethodGet, structures.RANK_PATH+"?year=1990", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	problem := structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, structures.ERR_NOT_FOUND, problem.Code)
+
+	//the ranking can be a csv like the other lists
+	rr = httptest.NewRecorder()
+	RankHandler(rr, httptest.NewRequest(http.MethodGet, structures.RANK_PATH+"?top=1&format=csv", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, structures.CSV_CONTENT_TYPE+"; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Entity,Code,Year,"+structures.RENEWABLESHEADER+",rank,percentile,previousRank,rankChange\n"+
+		"Norway,NOR,2021,72,1,100,1,0\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, structures.RANK_PATH, nil)
+	request.Header.Set("Accept", "image/png")
+	RankHandler(rr, request)
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/regions"
+	"groupXX/structures"
+)
+
+func RegionsHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		RegionsGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// lists the regions, ?kind= only lists one kind, or returns one region if its name is in the path
+func RegionsGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	name := strings.Trim(r.URL.Path[len(structures.REGIONS_PATH):], "/")
+	if name != "" {
+		region, ok := regions.Default.Get(name)
+		if !ok {
+			functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_REGION_NOT_FOUND,
+				"No region named '"+name+"'"))
+			return
+		}
+		functions.PrintData(w, withInData(region))
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	list := make([]structures.Region, 0)
+	for _, region := range regions.Default.All() {
+		if kind == "" || strings.EqualFold(kind, region.Kind) {
+			list = append(list, withInData(region))
+		}
+	}
+	functions.PrintData(w, list)
+}
+
+// marks if the data has its own row for the region
+func withInData(region structures.Region) structures.Region {
+	region.InData = functions.CurrentData().Has(region.Name)
+	return region
+}
+
+func AggregateHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		AggregateGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// computes a metric for ?region= or for ?countries= (a comma separated list), optionally weighted by ?weight=
+func AggregateGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	queryParams := r.URL.Query()
+
+	name := structures.CUSTOMREGION
+	var members []string
+	if regionName := queryParams.Get("region"); regionName != "" {
+		region, ok := regions.Default.Get(regionName)
+		if !ok {
+			functions.WriteError(w, r, functions.NewError(http.StatusNotFound, structures.ERR_REGION_NOT_FOUND,
+				"No region named '"+regionName+"'"))
+			return
+		}
+		name, members = region.Name, region.Members
+	} else if countriesStr := queryParams.Get("countries"); countriesStr != "" {
+		for _, country := range strings.Split(countriesStr, ",") {
+			if country = strings.TrimSpace(country); country != "" {
+				members = append(members, country)
+			}
+		}
+	}
+	if len(members) == 0 {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_MISSING_PARAMETER,
+			"Missing countries, expected ?region= or ?countries="))
+		return
+	}
+
+	_, metric, ok := requestedMetric(w, r)
+	if !ok {
+		return
+	}
+	weight := strings.ToLower(queryParams.Get("weight"))
+	if _, ok := functions.CurrentData().Metric(weight); weight != "" && !ok {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_UNKNOWN_METRIC,
+			"Unknown weight '"+weight+"', the loaded metrics are listed at "+structures.METRICS_PATH))
+		return
+	}
+
+	begin, end, ok := requestedYears(w, r)
+	if !ok {
+		return
+	}
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
+
+	aggregates, err := functions.Aggregate(name, members, metric, weight, begin, end)
+	if err != nil {
+		log.Printf("Error computing aggregate: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+	if aggregates == nil {
+		aggregates = []structures.AggregateEntry{}
+	}
+	functions.PrintFormat(w, aggregates, format)
+}
+
+// reads ?aggregates=, which decides if regions are listed together with the countries (true by default).
+// Writes a 400 and returns false if it isn't a bool
+func includeAggregates(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	aggregatesStr := r.URL.Query().Get("aggregates")
+	if aggregatesStr == "" {
+		return true, true
+	}
+	include, err := strconv.ParseBool(aggregatesStr)
+	if err != nil {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Error parsing aggregates value from string to bool: "+err.Error()))
+		return false, false
+	}
+	return include, true
+}
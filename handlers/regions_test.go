@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/regions"
+	"groupXX/structures"
+)
+
+func TestRegionsGetHandler(t *testing.T) {
+	defaultRegistry, defaultData := regions.Default, functions.CurrentData()
+	defer func() {
+		regions.Default = defaultRegistry
+		functions.SetData(defaultData)
+	}()
+	regions.Default = regions.New([]structures.Region{
+		{Name: "Nordics", Kind: "custom", Members: []string{"NOR", "SWE"}},
+		{Name: "Europe", Kind: structures.REGION_CONTINENT, Members: []string{"NOR", "SWE", "DEU"}},
+	})
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 70},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50},
+		{Country: "Europe", CountryCode: "", Year: 2021, Percentage: 23},
+	}))
+
+	rr := httptest.NewRecorder()
+	RegionsHandler(rr, httptest.NewRequest(http.MethodGet, structures.REGIONS_PATH+"?kind=continent", nil))
+	list := []structures.Region{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	assert.Equal(t, []structures.Region{{Name: "Europe", Kind: structures.REGION_CONTINENT, Members: []string{"NOR", "SWE", "DEU"}, InData: true}}, list)
+
+	rr = httptest.NewRecorder()
+	RegionsHandler(rr, httptest.NewRequest(http.MethodGet, structures.REGIONS_PATH+"nordics", nil))
+	region := structures.Region{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &region))
+	assert.Equal(t, "Nordics", region.Name)
+	assert.False(t, region.InData)
+
+	rr = httptest.NewRecorder()
+	RegionsHandler(rr, httptest.NewRequest(http.MethodGet, structures.REGIONS_PATH+"atlantis", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	AggregateHandler(rr, httptest.NewRequest(http.MethodGet, structures.AGGREGATE_PATH+"?region=nordics", nil))
+	aggregates := []structures.AggregateEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &aggregates))
+	assert.Equal(t, []structures.AggregateEntry{{Name: "Nordics", Year: 2021, Metric: structures.DEFAULTMETRIC, Value: 60, Countries: 2, Members: 2}}, aggregates)
+
+	rr = httptest.NewRecorder()
+	AggregateHandler(rr, httptest.NewRequest(http.MethodGet, structures.AGGREGATE_PATH+"?region=nordics&format=csv", nil))
+	assert.Equal(t, "Entity,Code,Year,"+structures.RENEWABLESHEADER+",weight,countries,members\nNordics,,2021,60,,2,2\n",
+		rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	AggregateHandler(rr, httptest.NewRequest(http.MethodGet, structures.AGGREGATE_PATH+"?countries=NOR,%20DEU", nil))
+	aggregates = []structures.AggregateEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &aggregates))
+	assert.Equal(t, structures.CUSTOMREGION, aggregates[0].Name)
+	assert.Equal(t, 1, aggregates[0].Countries)
+
+	rr = httptest.NewRecorder()
+	AggregateHandler(rr, httptest.NewRequest(http.MethodGet, structures.AGGREGATE_PATH, nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	AggregateHandler(rr, httptest.NewRequest(http.MethodGet, structures.AGGREGATE_PATH+"?region=nordics&weight=population", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	//every country without the regions
+	rr = httptest.NewRecorder()
+	HistoryHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLEHISTORY_PATH+"?aggregates=false", nil))
+	data := []structures.DataEntry{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &data))
+	assert.Len(t, data, 2)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		SearchGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// lists the countries matching ?q=, best matches first, ?limit=N sets how many (10 by default)
+func SearchGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_MISSING_PARAMETER,
+			"Missing search, expected ?q="))
+		return
+	}
+
+	limit := structures.MAXSEARCHRESULTS
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing limit value, expected a positive number"))
+			return
+		}
+	}
+
+	functions.PrintData(w, functions.SearchCountries(functions.CurrentData().Countries(), query, limit))
+}
+
+// writes a 404 with the countries closest to the search
+func NotFound(w http.ResponseWriter, r *http.Request, search string) {
+	functions.WriteError(w, r, functions.NewErrorWithDetails(http.StatusNotFound, structures.ERR_COUNTRY_NOT_FOUND,
+		"No return for the given search found", structures.NotFoundDetails{
+			Search:      search,
+			Suggestions: functions.Suggestions(functions.CurrentData().Countries(), search),
+		}))
+}
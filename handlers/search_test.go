@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TestSearchGetHandler(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2021},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021},
+	}))
+
+	rr := httptest.NewRecorder()
+	SearchHandler(rr, httptest.NewRequest(http.MethodGet, structures.SEARCH_PATH+"?q=swe", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	results := []structures.SearchResult{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+	assert.Equal(t, []structures.SearchResult{{Name: "Sweden", CountryCode: "SWE", Match: structures.MATCH_EXACT}}, results)
+
+	rr = httptest.NewRecorder()
+	SearchHandler(rr, httptest.NewRequest(http.MethodGet, structures.SEARCH_PATH, nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	SearchHandler(rr, httptest.NewRequest(http.MethodGet, structures.SEARCH_PATH+"?q=nor&limit=-1", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestNotFound(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021}}))
+
+	rr := httptest.NewRecorder()
+	NotFound(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+"Norwya", nil), "Norwya")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, structures.PROBLEM_CONTENT_TYPE, rr.Header().Get("Content-Type"))
+
+	body := struct {
+		structures.Problem
+		Details structures.NotFoundDetails `json:"details"`
+	}{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, http.StatusNotFound, body.Status)
+	assert.Equal(t, structures.ERR_COUNTRY_NOT_FOUND, body.Code)
+	assert.Equal(t, structures.RENEWABLECURRENT_PATH+"Norwya", body.Instance)
+	assert.Equal(t, "Norwya", body.Details.Search)
+	assert.Equal(t, []string{"Norway"}, body.Details.Suggestions)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"groupXX/functions"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+func StatsHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		StatsGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// lists the most requested countries, ?top=N sets how many (10 by default)
+func StatsGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	top := structures.DEFAULTTOP
+	if topStr := r.URL.Query().Get("top"); topStr != "" {
+		var err error
+		top, err = strconv.Atoi(topStr)
+		if err != nil || top < 1 {
+			functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+				"Error parsing top value, expected a positive number"))
+			return
+		}
+	}
+
+	calls, err := storage.DB.GetAllCalls(context.Background())
+	if err != nil {
+		functions.WriteError(w, r, err)
+		return
+	}
+
+	stats := structures.Stats{AllCountries: calls[structures.ALLCOUNTRIES], Countries: make([]structures.CountryCalls, 0)}
+	for code, count := range calls {
+		if code == structures.ALLCOUNTRIES {
+			continue
+		}
+		stats.Countries = append(stats.Countries, structures.CountryCalls{CountryCode: code, Calls: count})
+	}
+
+	//most calls first, ties by code so the order is stable
+	sort.Slice(stats.Countries, func(i, j int) bool {
+		if stats.Countries[i].Calls != stats.Countries[j].Calls {
+			return stats.Countries[i].Calls > stats.Countries[j].Calls
+		}
+		return stats.Countries[i].CountryCode < stats.Countries[j].CountryCode
+	})
+	if len(stats.Countries) > top {
+		stats.Countries = stats.Countries[:top]
+	}
+
+	//adds the names for the countries found in the data
+	for i, country := range stats.Countries {
+		matching, err := functions.ExtractByMap(country.CountryCode)
+		if err == nil && len(matching) > 0 {
+			stats.Countries[i].Name = matching[0].Country
+		}
+	}
+
+	functions.PrintData(w, stats)
+}
+
+// counts a call of every search which was answered, with the countries a request with neighbours read.
+// Counting the call isn't part of the answer, so the request goes on if it fails
+func countCalls(searches []string, distances map[string]int) {
+	for country, distance := range distances {
+		if distance > 0 {
+			searches = append(searches, country)
+		}
+	}
+	if err := functions.UpdateCalls(searches...); err != nil {
+		log.Printf("Error updating calls for %v: %v", searches, err)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries"
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+func TestStatsGetHandler(t *testing.T) {
+	storage.DB = storage.NewMemoryStore()
+	ctx := context.Background()
+	for country, count := range map[string]int{"NOR": 3, "SWE": 5, "DNK": 1, structures.ALLCOUNTRIES: 2} {
+		for i := 0; i < count; i++ {
+			storage.DB.IncrementCalls(ctx, country)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	StatsHandler(rr, httptest.NewRequest(http.MethodGet, structures.STATS_PATH+"?top=2", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	stats := structures.Stats{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	assert.Equal(t, int64(2), stats.AllCountries)
+	assert.Equal(t, []structures.CountryCalls{{CountryCode: "SWE", Calls: 5}, {CountryCode: "NOR", Calls: 3}}, stats.Countries)
+
+	rr = httptest.NewRecorder()
+	StatsHandler(rr, httptest.NewRequest(http.MethodGet, structures.STATS_PATH+"?top=zero", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestCallsCounted(t *testing.T) {
+	defaultResolver, defaultData := countries.Default(), functions.CurrentData()
+	defer func() {
+		countries.SetDefault(defaultResolver)
+		functions.SetData(defaultData)
+	}()
+	countries.SetDefault(countries.New([]structures.Country{
+		{Alpha3: "NOR", Borders: []string{"SWE"}, Name: structures.CountryName{Common: "Norway"}},
+		{Alpha3: "SWE", Borders: []string{"NOR"}, Name: structures.CountryName{Common: "Sweden"}},
+	}))
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 71.5},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.6},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2019, Percentage: 49.5},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2020, Percentage: 50.2},
+		{Country: "Sweden", CountryCode: "SWE", Year: 2021, Percentage: 50.9},
+	}))
+	storage.DB = storage.NewMemoryStore()
+
+	//a search which isn't found isn't counted, and the neighbours are counted with the country
+	for _, path := range []string{"atlantis", "norway?neighbours=true", "", "nor"} {
+		rr := httptest.NewRecorder()
+		CurrentHandler(rr, httptest.NewRequest(http.MethodGet, structures.RENEWABLECURRENT_PATH+path, nil))
+	}
+	//the trend and forecast endpoints count their country the same way
+	rr := httptest.NewRecorder()
+	TrendHandler(rr, httptest.NewRequest(http.MethodGet, structures.TREND_PATH+"sweden", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = httptest.NewRecorder()
+	ForecastHandler(rr, httptest.NewRequest(http.MethodGet, structures.FORECAST_PATH+"swe", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	calls, err := storage.DB.GetAllCalls(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"NOR": 2, "SWE": 3, structures.ALLCOUNTRIES: 1}, calls)
+}
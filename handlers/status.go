@@ -1,7 +1,5 @@
 package handlers
 
-//missing notification_db and webhooks!!!!!!!!!!!!!!!!!!!
-
 import (
 	"context"
 	"log"
@@ -9,8 +7,10 @@ import (
 	"strings"
 	"time"
 
-	"groupXX/firebase"
+	"groupXX/cache"
+	"groupXX/countries"
 	"groupXX/functions"
+	"groupXX/storage"
 	"groupXX/structures"
 )
 
@@ -23,8 +23,7 @@ func StatusHandler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		StatusGetHandler(w, r)
 	default:
-		http.Error(w, "This service only offers the <a href=\"/diag\">/diag endpoint</a> that shows comprehensive HTTP "+
-			"information for any received request. Please redirect any request to that endpoint.", http.StatusInternalServerError)
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
 		return
 	}
 }
@@ -32,40 +31,44 @@ func StatusHandler(w http.ResponseWriter, r *http.Request) {
 func StatusGetHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	//checks the status code for the URLs of the third party APIs
-	respCountry, err := http.Get("https://restcountries.com/")
-	if err != nil {
-		log.Printf("Error when getting country API: %v %s", respCountry, err)
-		http.Error(w, "Error when getting country API", http.StatusInternalServerError)
-		return
-	}
-	//closes its body at the end of the function
-	defer respCountry.Body.Close()
-
-	respNotif, err := http.Get("https://console.firebase.google.com/project/group66assignment2/firestore/data/~2Fwebhooks~2F45NVjImCjz2pGEDAPupD")
+	//checks the status code of the countries API the service is configured with, an API which can't be reached
+	//in time is reported as 503 so the rest of the status is still written
+	probeCtx, cancelProbe := context.WithTimeout(r.Context(), structures.STATUSPROBETIMEOUT)
+	defer cancelProbe()
+	countryStatus, err := countries.DefaultClient.Status(probeCtx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		log.Printf("Error when getting country API: %v", err)
+		countryStatus = http.StatusServiceUnavailable
 	}
-	defer respNotif.Body.Close()
 
-	ctx := context.Background()
-	client, err := firebase.CreateFirestoreClient(ctx)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-	numWh, err := firebase.GetNumWebhooks(ctx, client)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	//the storage backend in use answers with 200 if it is up and 503 if not, then the webhooks can't be counted
+	notifStatus, numWh := http.StatusOK, 0
+	ctx, cancel := context.WithTimeout(r.Context(), structures.STORAGEPINGTIMEOUT)
+	defer cancel()
+	if err := storage.DB.Ping(ctx); err != nil {
+		log.Printf("Error when pinging the %s storage: %v", storage.DB.Backend(), err)
+		notifStatus = http.StatusServiceUnavailable
+	} else {
+		numWh, err = storage.DB.GetNumWebhooks(ctx)
+		if err != nil {
+			functions.WriteError(w, r, err)
+			return
+		}
 	}
 
 	//fills the struct
 	output := structures.Info{
-		RESTStatus:  respCountry.StatusCode,
-		NotifStatus: respNotif.StatusCode,
+		RESTStatus:  countryStatus,
+		NotifStatus: notifStatus,
+		Storage:     storage.DB.Backend(),
 		Webhooks:    numWh,
 		Version:     strings.Split(r.URL.Path, "/")[2],
 		Uptime:      time.Now().Sub(startTime).Seconds(),
+		Cache:       cache.Searches.Stats(),
+	}
+	//state of the circuit breaker in front of the countries API, if it has one
+	if breaker, ok := countries.DefaultClient.(*countries.Breaker); ok {
+		output.CountriesBreaker = breaker.Stats()
 	}
 
 	//and prints it out
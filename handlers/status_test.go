@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries"
+	"groupXX/countries/countriestest"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+func TestStatusGetHandler(t *testing.T) {
+	server := countriestest.NewServer([]structures.Country{{Alpha3: "NOR", Name: structures.CountryName{Common: "Norway"}}})
+	defer server.Close()
+	defaultClient := countries.DefaultClient
+	defer func() { countries.DefaultClient = defaultClient }()
+	countries.DefaultClient = countries.NewBreaker(countries.NewHTTPClient(server.BaseURL))
+	storage.DB = storage.NewMemoryStore()
+
+	//the configured countries API and the storage in use are checked
+	rr := httptest.NewRecorder()
+	StatusHandler(rr, httptest.NewRequest(http.MethodGet, structures.STATUS_PATH, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	info := structures.Info{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &info))
+	assert.Equal(t, http.StatusOK, info.RESTStatus)
+	assert.Equal(t, http.StatusOK, info.NotifStatus)
+	assert.Equal(t, structures.STORAGE_MEMORY, info.Storage)
+	assert.Equal(t, structures.BREAKER_CLOSED, info.CountriesBreaker.State)
+	assert.Equal(t, "/v3.1/all", server.Requests()[0].URL.Path)
+
+	server.Fail(http.StatusServiceUnavailable)
+	rr = httptest.NewRecorder()
+	StatusHandler(rr, httptest.NewRequest(http.MethodGet, structures.STATUS_PATH, nil))
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &info))
+	assert.Equal(t, http.StatusServiceUnavailable, info.RESTStatus)
+
+	//an API which can't be reached is reported as unavailable, together with the state of the breaker
+	server.Close()
+	rr = httptest.NewRecorder()
+	StatusHandler(rr, httptest.NewRequest(http.MethodGet, structures.STATUS_PATH, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	info = structures.Info{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &info))
+	assert.Equal(t, http.StatusServiceUnavailable, info.RESTStatus)
+	assert.Equal(t, structures.BREAKER_CLOSED, info.CountriesBreaker.State)
+	assert.Equal(t, http.StatusOK, info.NotifStatus)
+	assert.Contains(t, rr.Body.String(), `"countries_breaker"`)
+}
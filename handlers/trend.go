@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TrendHandler(w http.ResponseWriter, r *http.Request) {
+	//takes method of the request, if GET then forward to function, else write to the user that only GET is allowed
+	switch r.Method {
+	case http.MethodGet:
+		TrendGetHandler(w, r)
+	default:
+		functions.WriteError(w, r, functions.MethodNotSupported(r.Method, http.MethodGet))
+		return
+	}
+}
+
+// returns the trend statistics of a country from ?begin= to ?end=, both optional like on the history endpoint,
+// or for ?year= as a range like 2010-2020
+func TrendGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	country := strings.Trim(r.URL.Path[len(structures.TREND_PATH):], "/")
+	if country == "" {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_MISSING_PARAMETER,
+			"Missing country, expected "+structures.TREND_PATH+"{country}"))
+		return
+	}
+
+	begin, end, ok := requestedYears(w, r)
+	if !ok {
+		return
+	}
+
+	_, metric, ok := requestedMetric(w, r)
+	if !ok {
+		return
+	}
+
+	//?format= or the Accept header picks csv, ndjson or xlsx instead of JSON
+	format, ok := requestedFormat(w, r)
+	if !ok {
+		return
+	}
+	//the same entries as the history endpoint shows for the country and years
+	data, err := functions.ReadMetricInfo(metric, country, false, begin, end)
+	if err != nil {
+		log.Printf("Error reading CSV file: %v", err)
+		functions.WriteError(w, r, err)
+		return
+	}
+	//a known country without any of the years asked for is a bad range, not a country which isn't found
+	if data == nil {
+		NotFoundInYears(w, r, country)
+		return
+	}
+
+	trend, err := functions.Trend(data, metric)
+	if err != nil {
+		functions.WriteError(w, r, err)
+		return
+	}
+	countCalls([]string{country}, nil)
+	functions.PrintFormat(w, trend, format)
+}
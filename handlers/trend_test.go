@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/dataset"
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+func TestTrendGetHandler(t *testing.T) {
+	defaultData := functions.CurrentData()
+	defer functions.SetData(defaultData)
+	functions.SetData(dataset.New([]structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR", Year: 2019, Percentage: 60},
+		{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 65},
+		{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 70},
+	}))
+
+	rr := httptest.NewRecorder()
+	TrendHandler(rr, httptest.NewRequest(http.MethodGet, structures.TREND_PATH+"nor?begin=2020", nil))
+	trend := structures.Trend{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trend))
+	assert.Equal(t, 2, trend.Years)
+	assert.Equal(t, 5.0, trend.AbsoluteChange)
+	assert.Equal(t, 5.0, trend.Slope)
+	assert.Equal(t, 1.0, *trend.RSquared)
+
+	rr = httptest.NewRecorder()
+	TrendHandler(rr, httptest.NewRequest(http.MethodGet, structures.TREND_PATH+"nor?begin=2021", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	TrendHandler(rr, httptest.NewRequest(http.MethodGet, structures.TREND_PATH+"nor?end=soon", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	//a known country without any of the years is a bad range, which names the years there are
+	rr = httptest.NewRecorder()
+	TrendHandler(rr, httptest.NewRequest(http.MethodGet, structures.TREND_PATH+"nor?begin=1990&end=2000", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	problem := structures.Problem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, structures.ERR_INSUFFICIENT_DATA, problem.Code)
+	assert.Contains(t, problem.Detail, "from 2019 to 2021")
+
+	rr = httptest.NewRecorder()
+	TrendHandler(rr, httptest.NewRequest(http.MethodGet, structures.TREND_PATH+"atlantis", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"groupXX/functions"
+	"groupXX/structures"
+)
+
+// reads the years a request is for, either ?year= as one year or a range like 2010-2020, or ?begin= and ?end=.
+// Every one is optional, and a year which isn't given is nil. Writes a 400 and returns false if they aren't years
+func requestedYears(w http.ResponseWriter, r *http.Request) (*int, *int, bool) {
+	queryParams := r.URL.Query()
+	begin, end, err := parseYearRange(queryParams.Get("year"))
+	if err == nil && begin == nil {
+		begin, err = parseYear(queryParams.Get("begin"))
+		if err == nil {
+			end, err = parseYear(queryParams.Get("end"))
+		}
+	}
+	if err != nil {
+		functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INVALID_PARAMETER,
+			"Error parsing the years, expected year as a year or a range like 2010-2020, or begin and end as years"))
+		return nil, nil, false
+	}
+	return begin, end, true
+}
+
+// answers a search which found nothing, with the years the country has if it is in the data and only the years
+// asked for had nothing, and as a country which isn't found otherwise
+func NotFoundInYears(w http.ResponseWriter, r *http.Request, country string) {
+	entries := functions.CurrentData().Country(country)
+	if len(entries) == 0 {
+		NotFound(w, r, country)
+		return
+	}
+	functions.WriteError(w, r, functions.NewError(http.StatusBadRequest, structures.ERR_INSUFFICIENT_DATA,
+		entries[0].Country+" has no data for the years asked for, only from "+strconv.Itoa(entries[0].Year)+
+			" to "+strconv.Itoa(entries[len(entries)-1].Year)))
+}
+
+// parses "2021" or "2010-2020" into the first and last year, both nil if the value is empty
+func parseYearRange(value string) (*int, *int, error) {
+	if value == "" {
+		return nil, nil, nil
+	}
+	parts := strings.SplitN(value, "-", 2)
+	begin, err := parseYear(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	end := begin
+	if len(parts) == 2 {
+		end, err = parseYear(parts[1])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if begin == nil || end == nil {
+		return nil, nil, strconv.ErrSyntax
+	}
+	return begin, end, nil
+}
+
+// parses one year, nil if the value is empty
+func parseYear(value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	year, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &year, nil
+}
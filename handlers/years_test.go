@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseYearRange(t *testing.T) {
+	begin, end, err := parseYearRange("2021")
+	assert.NoError(t, err)
+	assert.Equal(t, 2021, *begin)
+	assert.Equal(t, 2021, *end)
+
+	begin, end, err = parseYearRange("2010-2020")
+	assert.NoError(t, err)
+	assert.Equal(t, 2010, *begin)
+	assert.Equal(t, 2020, *end)
+
+	begin, end, err = parseYearRange("")
+	assert.NoError(t, err)
+	assert.Nil(t, begin)
+	assert.Nil(t, end)
+
+	_, _, err = parseYearRange("2010-")
+	assert.Error(t, err)
+	_, _, err = parseYearRange("last")
+	assert.Error(t, err)
+}
+
+func TestRequestedYears(t *testing.T) {
+	rr := httptest.NewRecorder()
+	begin, end, ok := requestedYears(rr, httptest.NewRequest(http.MethodGet, "/?year=2010-2020", nil))
+	assert.True(t, ok)
+	assert.Equal(t, 2010, *begin)
+	assert.Equal(t, 2020, *end)
+
+	begin, end, ok = requestedYears(rr, httptest.NewRequest(http.MethodGet, "/?begin=2015", nil))
+	assert.True(t, ok)
+	assert.Equal(t, 2015, *begin)
+	assert.Nil(t, end)
+
+	begin, end, ok = requestedYears(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, ok)
+	assert.Nil(t, begin)
+	assert.Nil(t, end)
+
+	//every endpoint answers a year which isn't a year the same way
+	for _, query := range []string{"?year=recent", "?begin=x", "?end=soon"} {
+		rr = httptest.NewRecorder()
+		_, _, ok = requestedYears(rr, httptest.NewRequest(http.MethodGet, "/"+query, nil))
+		assert.False(t, ok, query)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+		assert.Contains(t, rr.Body.String(), "invalid_parameter", query)
+	}
+}
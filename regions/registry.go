@@ -0,0 +1,93 @@
+package regions
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"groupXX/countries"
+	"groupXX/structures"
+)
+
+// Registry knows which names in the data are aggregates of countries rather than countries, and their members
+type Registry struct {
+	regions []structures.Region
+	//normalised name -> index in regions
+	byName map[string]int
+}
+
+// the registry used by the service, nil if the regions file couldn't be loaded
+var Default *Registry
+
+func init() {
+	var err error
+	Default, err = Load(structures.REGIONSFILE)
+	if err != nil {
+		log.Printf("Error loading regions file: %v", err)
+	}
+}
+
+// loads the regions from a file
+func Load(path string) (*Registry, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var regions []structures.Region
+	err = json.Unmarshal(content, &regions)
+	if err != nil {
+		return nil, err
+	}
+	return New(regions), nil
+}
+
+// builds a registry from a list of regions
+func New(regions []structures.Region) *Registry {
+	r := &Registry{regions: regions, byName: make(map[string]int)}
+	for i, region := range regions {
+		r.byName[countries.Normalise(region.Name)] = i
+	}
+	return r
+}
+
+// returns the region with the name, regardless of case and punctuation, and if it was found
+func (r *Registry) Get(name string) (structures.Region, bool) {
+	if r == nil {
+		return structures.Region{}, false
+	}
+	i, ok := r.byName[countries.Normalise(name)]
+	if !ok {
+		return structures.Region{}, false
+	}
+	return r.regions[i], true
+}
+
+// returns every region in the order of the file
+func (r *Registry) All() []structures.Region {
+	if r == nil {
+		return nil
+	}
+	return r.regions
+}
+
+// reports if an entry of the data is an aggregate. Aggregates have no iso code or an OWID_ code
+// like OWID_WRL for the world, and the registry covers the ones which are named but not coded
+func IsAggregate(entry structures.DataEntry) bool {
+	if entry.CountryCode == "" || strings.HasPrefix(entry.CountryCode, "OWID_") {
+		return true
+	}
+	_, ok := Default.Get(entry.Country)
+	return ok
+}
+
+// returns the entries which are countries
+func ExcludeAggregates(data []structures.DataEntry) []structures.DataEntry {
+	var kept []structures.DataEntry
+	for _, entry := range data {
+		if !IsAggregate(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
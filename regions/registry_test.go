@@ -0,0 +1,88 @@
+package regions
+
+import (
+	"encoding/csv"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/countries"
+	"groupXX/structures"
+)
+
+func loadRegistry(t *testing.T) *Registry {
+	registry, err := Load("../structures/regions.json")
+	if err != nil {
+		t.Fatalf("Load() returned an error: %v", err)
+	}
+	return registry
+}
+
+func TestGet(t *testing.T) {
+	registry := loadRegistry(t)
+
+	region, ok := registry.Get("european union 27")
+	assert.True(t, ok)
+	assert.Equal(t, "European Union (27)", region.Name)
+	assert.Equal(t, structures.REGION_POLITICAL, region.Kind)
+	assert.Len(t, region.Members, 27)
+	assert.Contains(t, region.Members, "SWE")
+	assert.NotContains(t, region.Members, "NOR")
+
+	region, ok = registry.Get("Europe")
+	assert.True(t, ok)
+	assert.Equal(t, structures.REGION_CONTINENT, region.Kind)
+	assert.Contains(t, region.Members, "NOR")
+
+	_, ok = registry.Get("Norway")
+	assert.False(t, ok)
+
+	var nilRegistry *Registry
+	_, ok = nilRegistry.Get("Europe")
+	assert.False(t, ok)
+}
+
+func TestRegionsAreConsistent(t *testing.T) {
+	registry := loadRegistry(t)
+	resolver, err := countries.Load("../structures/countries.json")
+	if err != nil {
+		t.Fatalf("Loading countries failed: %v", err)
+	}
+
+	//every member is a known country
+	for _, region := range registry.All() {
+		for _, member := range region.Members {
+			country, ok := resolver.Resolve(member)
+			assert.True(t, ok && country.Alpha3 == member, "%s in %s", member, region.Name)
+		}
+	}
+
+	//every aggregate in the data is in the registry
+	file, err := os.Open("../structures/energyData.csv")
+	if err != nil {
+		t.Skipf("Energy data not available: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	assert.NoError(t, err)
+	for _, record := range records[1:] {
+		if record[1] == "" || strings.HasPrefix(record[1], "OWID_") {
+			_, ok := registry.Get(record[0])
+			assert.True(t, ok, record[0])
+		}
+	}
+}
+
+func TestExcludeAggregates(t *testing.T) {
+	data := []structures.DataEntry{
+		{Country: "Norway", CountryCode: "NOR"},
+		{Country: "Europe", CountryCode: ""},
+		{Country: "World", CountryCode: "OWID_WRL"},
+		{Country: "Sweden", CountryCode: "SWE"},
+	}
+	assert.Equal(t, []structures.DataEntry{data[0], data[3]}, ExcludeAggregates(data))
+	assert.True(t, IsAggregate(data[1]))
+	assert.False(t, IsAggregate(data[0]))
+}
@@ -0,0 +1,88 @@
+// Package signature signs webhook payloads and lets receivers verify that an invocation came from the service.
+//
+// Every delivery to a webhook registered with a secret has the headers
+//
+//	X-Signature-Timestamp: <unix seconds when the attempt was made>
+//	X-Signature: sha256=<hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret>
+//
+// A receiver written in Go can check both with VerifyRequest:
+//
+//	body, err := signature.VerifyRequest(r, secret, 5*time.Minute)
+//	if err != nil {
+//		http.Error(w, err.Error(), http.StatusUnauthorized)
+//		return
+//	}
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// headers set on every signed delivery
+const HeaderSignature = "X-Signature"
+const HeaderTimestamp = "X-Signature-Timestamp"
+
+// prefix of the signature header value, naming the algorithm
+const prefix = "sha256="
+
+var ErrMissingSignature = errors.New("missing signature headers")
+var ErrInvalidSignature = errors.New("signature does not match payload")
+var ErrExpiredTimestamp = errors.New("signature timestamp outside of tolerance")
+
+// returns the value of the signature header for the body sent at the given unix time
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// checks the signature header value against the body and timestamp header value
+func Verify(secret string, signature string, timestamp string, body []byte) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(signature, prefix) {
+		return ErrInvalidSignature
+	}
+	//constant time comparison so the signature can't be guessed byte by byte
+	if !hmac.Equal([]byte(Sign(secret, unix, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifies a received request and returns its body, which is also put back so the request can be read again.
+// tolerance is how old the timestamp may be, to stop old deliveries from being replayed, 0 turns the check off
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	timestamp := r.Header.Get(HeaderTimestamp)
+	if err := Verify(secret, r.Header.Get(HeaderSignature), timestamp, body); err != nil {
+		return nil, err
+	}
+	if tolerance > 0 {
+		unix, _ := strconv.ParseInt(timestamp, 10, 64)
+		age := time.Since(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return nil, ErrExpiredTimestamp
+		}
+	}
+	return body, nil
+}
@@ -0,0 +1,48 @@
+package signature
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"url":"http://localhost","country":"NOR","calls":5}`)
+	sig := Sign("secret", 1681984800, body)
+
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", sig)
+	assert.NoError(t, Verify("secret", sig, "1681984800", body))
+	assert.Equal(t, ErrInvalidSignature, Verify("other", sig, "1681984800", body))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", sig, "1681984801", body))
+	assert.Equal(t, ErrInvalidSignature, Verify("secret", sig, "1681984800", append(body, ' ')))
+	assert.Equal(t, ErrMissingSignature, Verify("secret", "", "1681984800", body))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := []byte(`{"country":"NOR"}`)
+	now := time.Now().Unix()
+
+	req := httptest.NewRequest("POST", "/hook", bytes.NewReader(body))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now, 10))
+	req.Header.Set(HeaderSignature, Sign("secret", now, body))
+
+	verified, err := VerifyRequest(req, "secret", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, body, verified)
+
+	//the body can still be read by the receiver
+	again, _ := ioutil.ReadAll(req.Body)
+	assert.Equal(t, body, again)
+
+	old := now - 3600
+	req = httptest.NewRequest("POST", "/hook", bytes.NewReader(body))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(old, 10))
+	req.Header.Set(HeaderSignature, Sign("secret", old, body))
+	_, err = VerifyRequest(req, "secret", time.Minute)
+	assert.Equal(t, ErrExpiredTimestamp, err)
+}
@@ -0,0 +1,396 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"groupXX/structures"
+)
+
+// names of the buckets in the bolt file, same as the firestore collections
+var (
+	webhookBucket    = []byte("webhooks")
+	deliveryBucket   = []byte("deliveries")
+	deadLetterBucket = []byte("deadletters")
+	callsBucket      = []byte("calls")
+	cacheBucket      = []byte("cache")
+)
+
+// BoltStore keeps everything in a single file on disk, so data survives restarts without any external service
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// opens (or creates) the bolt file at the given path
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	//makes sure the buckets exists so the other functions don't have to check
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{webhookBucket, deliveryBucket, deadLetterBucket, callsBucket, cacheBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// opens a read transaction, which fails once the file is closed
+func (s *BoltStore) Ping(ctx context.Context) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(webhookBucket) == nil {
+			return fmt.Errorf("bolt file has no %s bucket", webhookBucket)
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Backend() string {
+	return structures.STORAGE_BOLT
+}
+
+// stores a new webhook under a random id
+func (s *BoltStore) StoreWebhook(ctx context.Context, webhook structures.Webhook) (string, error) {
+	id := uuid.NewString()
+	encoded, err := json.Marshal(webhook)
+	if err != nil {
+		return "", err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(webhookBucket).Put([]byte(id), encoded)
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *BoltStore) GetWebhook(ctx context.Context, id string) (structures.Webhook, error) {
+	wh := structures.Webhook{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		encoded := tx.Bucket(webhookBucket).Get([]byte(id))
+		if encoded == nil {
+			return structures.ErrWebhookNotFound
+		}
+		return json.Unmarshal(encoded, &wh)
+	})
+	return wh, err
+}
+
+// replaces an existing webhook
+func (s *BoltStore) UpdateWebhook(ctx context.Context, id string, webhook structures.Webhook) error {
+	encoded, err := json.Marshal(webhook)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(webhookBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return structures.ErrWebhookNotFound
+		}
+		return bucket.Put([]byte(id), encoded)
+	})
+}
+
+// reads and writes the webhook in one transaction, so an update at the same time isn't lost
+func (s *BoltStore) SetWebhookState(ctx context.Context, id string, state string) error {
+	return s.changeWebhook(id, func(webhook *structures.Webhook) { webhook.LastState = state })
+}
+
+// like SetWebhookState, for the secret
+func (s *BoltStore) SetWebhookSecret(ctx context.Context, id string, secret string) error {
+	return s.changeWebhook(id, func(webhook *structures.Webhook) { webhook.Secret = secret })
+}
+
+// reads, changes and writes a webhook in one transaction
+func (s *BoltStore) changeWebhook(id string, change func(webhook *structures.Webhook)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(webhookBucket)
+		stored := bucket.Get([]byte(id))
+		if stored == nil {
+			return structures.ErrWebhookNotFound
+		}
+		webhook := structures.Webhook{}
+		if err := json.Unmarshal(stored, &webhook); err != nil {
+			return err
+		}
+		change(&webhook)
+		encoded, err := json.Marshal(webhook)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(id), encoded)
+	})
+}
+
+// deletes the webhook and the bucket of its delivery attempts in one transaction
+func (s *BoltStore) DeleteWebhook(ctx context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(webhookBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return structures.ErrWebhookNotFound
+		}
+		err := tx.Bucket(deliveryBucket).DeleteBucket([]byte(id))
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// returns the webhooks in key order
+func (s *BoltStore) GetAllWebhooks(ctx context.Context) ([]structures.WebhookRegistration, error) {
+	webhooks := make([]structures.WebhookRegistration, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(webhookBucket).ForEach(func(k, v []byte) error {
+			wh := structures.Webhook{}
+			if err := json.Unmarshal(v, &wh); err != nil {
+				return err
+			}
+			webhooks = append(webhooks, structures.WebhookRegistration{ID: string(k), Webhook: wh})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (s *BoltStore) GetNumWebhooks(ctx context.Context) (int, error) {
+	num := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		num = tx.Bucket(webhookBucket).Stats().KeyN
+		return nil
+	})
+	return num, err
+}
+
+// records a delivery attempt in the webhooks own bucket, only the latest MAXDELIVERYRECORDS are kept
+func (s *BoltStore) AddDelivery(ctx context.Context, delivery structures.Delivery) error {
+	encoded, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(deliveryBucket).CreateBucketIfNotExists([]byte(delivery.WebhookID))
+		if err != nil {
+			return err
+		}
+		//big endian sequence numbers keep the keys in the order they were added
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := bucket.Put(key, encoded); err != nil {
+			return err
+		}
+
+		//the oldest records are first, collected before deleting since the cursor can't delete while iterating
+		var keys [][]byte
+		bucket.ForEach(func(k, v []byte) error {
+			keys = append(keys, append([]byte(nil), k...))
+			return nil
+		})
+		for i := 0; i < len(keys)-structures.MAXDELIVERYRECORDS; i++ {
+			if err := bucket.Delete(keys[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// returns the delivery attempts of a webhook, oldest first
+func (s *BoltStore) GetDeliveries(ctx context.Context, webhookID string) ([]structures.Delivery, error) {
+	deliveries := make([]structures.Delivery, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(deliveryBucket).Bucket([]byte(webhookID))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			delivery := structures.Delivery{}
+			if err := json.Unmarshal(v, &delivery); err != nil {
+				return err
+			}
+			deliveries = append(deliveries, delivery)
+			return nil
+		})
+	})
+	return deliveries, err
+}
+
+func (s *BoltStore) AddDeadLetter(ctx context.Context, letter structures.DeadLetter) (string, error) {
+	letter.ID = uuid.NewString()
+	encoded, err := json.Marshal(letter)
+	if err != nil {
+		return "", err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).Put([]byte(letter.ID), encoded)
+	})
+	if err != nil {
+		return "", err
+	}
+	return letter.ID, nil
+}
+
+// returns the dead letters of a webhook, oldest first
+func (s *BoltStore) GetDeadLetters(ctx context.Context, webhookID string) ([]structures.DeadLetter, error) {
+	letters := make([]structures.DeadLetter, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deadLetterBucket).ForEach(func(k, v []byte) error {
+			letter := structures.DeadLetter{}
+			if err := json.Unmarshal(v, &letter); err != nil {
+				return err
+			}
+			if letter.WebhookID == webhookID {
+				letters = append(letters, letter)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].Time.Before(letters[j].Time) })
+	return letters, nil
+}
+
+func (s *BoltStore) DeleteDeadLetter(ctx context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(deadLetterBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return structures.ErrDeadLetterNotFound
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// increments the call counter of the country and returns the new count
+func (s *BoltStore) IncrementCalls(ctx context.Context, country string) (int64, error) {
+	var count uint64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(callsBucket)
+		if encoded := bucket.Get([]byte(country)); encoded != nil {
+			count = binary.BigEndian.Uint64(encoded)
+		}
+		count++
+		encoded := make([]byte, 8)
+		binary.BigEndian.PutUint64(encoded, count)
+		return bucket.Put([]byte(country), encoded)
+	})
+	return int64(count), err
+}
+
+func (s *BoltStore) GetAllCalls(ctx context.Context) (map[string]int64, error) {
+	calls := make(map[string]int64)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(callsBucket).ForEach(func(k, v []byte) error {
+			calls[string(k)] = int64(binary.BigEndian.Uint64(v))
+			return nil
+		})
+	})
+	return calls, err
+}
+
+// places the data in the cache, keeping the hit count if the key already is there
+func (s *BoltStore) SetCachedData(ctx context.Context, cacheKey string, data []structures.DataEntry) error {
+	cacheKey = strings.ToLower(cacheKey)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(cacheBucket)
+		entry := structures.CacheEntry{Key: cacheKey, Hits: 1}
+		if encoded := bucket.Get([]byte(cacheKey)); encoded != nil {
+			if err := json.Unmarshal(encoded, &entry); err != nil {
+				return err
+			}
+		}
+		entry.Data = data
+		entry.Timestamp = time.Now()
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(cacheKey), encoded)
+	})
+}
+
+// gets cached data and increments its hit count, nil if it isn't cached
+func (s *BoltStore) GetCachedData(ctx context.Context, cacheKey string) (*structures.CacheEntry, error) {
+	cacheKey = strings.ToLower(cacheKey)
+	var found *structures.CacheEntry
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(cacheBucket)
+		encoded := bucket.Get([]byte(cacheKey))
+		if encoded == nil {
+			return nil
+		}
+		entry := structures.CacheEntry{}
+		if err := json.Unmarshal(encoded, &entry); err != nil {
+			return err
+		}
+		entry.Hits++
+		found = &entry
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(cacheKey), encoded)
+	})
+	return found, err
+}
+
+// deletes caches older than a specific treshold
+func (s *BoltStore) PurgeOldCacheEntries(ctx context.Context, daysThreshold int) (int, error) {
+	limit := time.Now().AddDate(0, 0, -daysThreshold)
+	deleted := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(cacheBucket)
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			entry := structures.CacheEntry{}
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			if entry.Timestamp.Before(limit) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		//deleting while iterating with ForEach is not allowed, so it is done afterwards
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"groupXX/structures"
+)
+
+// MemoryStore keeps everything in maps, it is lost when the process stops
+type MemoryStore struct {
+	mu          sync.Mutex
+	webhooks    map[string]structures.Webhook
+	deliveries  map[string][]structures.Delivery
+	deadLetters map[string]structures.DeadLetter
+	calls       map[string]int64
+	cache       map[string]*structures.CacheEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		webhooks:    make(map[string]structures.Webhook),
+		deliveries:  make(map[string][]structures.Delivery),
+		deadLetters: make(map[string]structures.DeadLetter),
+		calls:       make(map[string]int64),
+		cache:       make(map[string]*structures.CacheEntry),
+	}
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// the maps are always there
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) Backend() string {
+	return structures.STORAGE_MEMORY
+}
+
+// stores a new webhook under a random id
+func (s *MemoryStore) StoreWebhook(ctx context.Context, webhook structures.Webhook) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := uuid.NewString()
+	s.webhooks[id] = webhook
+	return id, nil
+}
+
+func (s *MemoryStore) GetWebhook(ctx context.Context, id string) (structures.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wh, ok := s.webhooks[id]
+	if !ok {
+		return structures.Webhook{}, structures.ErrWebhookNotFound
+	}
+	return wh, nil
+}
+
+// replaces an existing webhook
+func (s *MemoryStore) UpdateWebhook(ctx context.Context, id string, webhook structures.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return structures.ErrWebhookNotFound
+	}
+	s.webhooks[id] = webhook
+	return nil
+}
+
+func (s *MemoryStore) SetWebhookState(ctx context.Context, id string, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return structures.ErrWebhookNotFound
+	}
+	webhook.LastState = state
+	s.webhooks[id] = webhook
+	return nil
+}
+
+func (s *MemoryStore) SetWebhookSecret(ctx context.Context, id string, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return structures.ErrWebhookNotFound
+	}
+	webhook.Secret = secret
+	s.webhooks[id] = webhook
+	return nil
+}
+
+func (s *MemoryStore) DeleteWebhook(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return structures.ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+	delete(s.deliveries, id)
+	return nil
+}
+
+// returns the webhooks sorted by id so the order is the same between calls
+func (s *MemoryStore) GetAllWebhooks(ctx context.Context) ([]structures.WebhookRegistration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhooks := make([]structures.WebhookRegistration, 0, len(s.webhooks))
+	for id, wh := range s.webhooks {
+		webhooks = append(webhooks, structures.WebhookRegistration{ID: id, Webhook: wh})
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (s *MemoryStore) GetNumWebhooks(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.webhooks), nil
+}
+
+// records a delivery attempt, only the latest MAXDELIVERYRECORDS are kept per webhook
+func (s *MemoryStore) AddDelivery(ctx context.Context, delivery structures.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deliveries := append(s.deliveries[delivery.WebhookID], delivery)
+	if len(deliveries) > structures.MAXDELIVERYRECORDS {
+		deliveries = deliveries[len(deliveries)-structures.MAXDELIVERYRECORDS:]
+	}
+	s.deliveries[delivery.WebhookID] = deliveries
+	return nil
+}
+
+// returns the delivery attempts of a webhook, oldest first
+func (s *MemoryStore) GetDeliveries(ctx context.Context, webhookID string) ([]structures.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]structures.Delivery{}, s.deliveries[webhookID]...), nil
+}
+
+func (s *MemoryStore) AddDeadLetter(ctx context.Context, letter structures.DeadLetter) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letter.ID = uuid.NewString()
+	s.deadLetters[letter.ID] = letter
+	return letter.ID, nil
+}
+
+// returns the dead letters of a webhook, oldest first
+func (s *MemoryStore) GetDeadLetters(ctx context.Context, webhookID string) ([]structures.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letters := make([]structures.DeadLetter, 0)
+	for _, letter := range s.deadLetters {
+		if letter.WebhookID == webhookID {
+			letters = append(letters, letter)
+		}
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].Time.Before(letters[j].Time) })
+	return letters, nil
+}
+
+func (s *MemoryStore) DeleteDeadLetter(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.deadLetters[id]; !ok {
+		return structures.ErrDeadLetterNotFound
+	}
+	delete(s.deadLetters, id)
+	return nil
+}
+
+// increments the call counter of the country and returns the new count
+func (s *MemoryStore) IncrementCalls(ctx context.Context, country string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[country]++
+	return s.calls[country], nil
+}
+
+func (s *MemoryStore) GetAllCalls(ctx context.Context) (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	calls := make(map[string]int64, len(s.calls))
+	for country, count := range s.calls {
+		calls[country] = count
+	}
+	return calls, nil
+}
+
+// places the data in the cache, keeping the hit count if the key already is there
+func (s *MemoryStore) SetCachedData(ctx context.Context, cacheKey string, data []structures.DataEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cacheKey = strings.ToLower(cacheKey)
+	copied := append([]structures.DataEntry(nil), data...)
+	if entry, ok := s.cache[cacheKey]; ok {
+		entry.Data = copied
+		entry.Timestamp = time.Now()
+		return nil
+	}
+	s.cache[cacheKey] = &structures.CacheEntry{Key: cacheKey, Data: copied, Timestamp: time.Now(), Hits: 1}
+	return nil
+}
+
+// gets cached data and increments its hit count, nil if it isn't cached
+func (s *MemoryStore) GetCachedData(ctx context.Context, cacheKey string) (*structures.CacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[strings.ToLower(cacheKey)]
+	if !ok {
+		return nil, nil
+	}
+	entry.Hits++
+	copied := *entry
+	copied.Data = append([]structures.DataEntry(nil), entry.Data...)
+	return &copied, nil
+}
+
+// deletes caches older than a specific treshold
+func (s *MemoryStore) PurgeOldCacheEntries(ctx context.Context, daysThreshold int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := time.Now().AddDate(0, 0, -daysThreshold)
+	deleted := 0
+	for key, entry := range s.cache {
+		if entry.Timestamp.Before(limit) {
+			delete(s.cache, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
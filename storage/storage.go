@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"groupXX/firebase"
+	"groupXX/structures"
+)
+
+// Store is implemented by every backend which can hold the webhooks and the search cache
+type Store interface {
+	StoreWebhook(ctx context.Context, webhook structures.Webhook) (string, error)
+	GetWebhook(ctx context.Context, id string) (structures.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, webhook structures.Webhook) error
+	//sets only the last state of a threshold webhook, so it can't undo an update of the rest of the webhook
+	SetWebhookState(ctx context.Context, id string, state string) error
+	//sets only the secret of a webhook, so it can't undo a change of the state made at the same time
+	SetWebhookSecret(ctx context.Context, id string, secret string) error
+	DeleteWebhook(ctx context.Context, id string) error
+	GetAllWebhooks(ctx context.Context) ([]structures.WebhookRegistration, error)
+	GetNumWebhooks(ctx context.Context) (int, error)
+
+	AddDelivery(ctx context.Context, delivery structures.Delivery) error
+	GetDeliveries(ctx context.Context, webhookID string) ([]structures.Delivery, error)
+	AddDeadLetter(ctx context.Context, letter structures.DeadLetter) (string, error)
+	GetDeadLetters(ctx context.Context, webhookID string) ([]structures.DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, id string) error
+
+	IncrementCalls(ctx context.Context, country string) (int64, error)
+	GetAllCalls(ctx context.Context) (map[string]int64, error)
+
+	SetCachedData(ctx context.Context, cacheKey string, data []structures.DataEntry) error
+	GetCachedData(ctx context.Context, cacheKey string) (*structures.CacheEntry, error)
+	PurgeOldCacheEntries(ctx context.Context, daysThreshold int) (int, error)
+
+	//checks that the backend answers, and the name of the backend for the status endpoint
+	Ping(ctx context.Context) error
+	Backend() string
+
+	Close() error
+}
+
+// the store used by the service, in memory until the server opens the configured one
+var DB Store = NewMemoryStore()
+
+// opens the backend with the given name
+func Open(ctx context.Context, backend string) (Store, error) {
+	switch backend {
+	case structures.STORAGE_FIRESTORE:
+		return firebase.NewStore(ctx, getEnv("FIRESTORE_PROJECT", structures.FIRESTORE_PROJECT),
+			getEnv("FIRESTORE_CREDENTIALS", structures.FIRESTORE_CREDENTIALS))
+	case structures.STORAGE_MEMORY:
+		return NewMemoryStore(), nil
+	case structures.STORAGE_BOLT:
+		return NewBoltStore(getEnv("BOLT_PATH", structures.BOLTFILE))
+	default:
+		return nil, fmt.Errorf("Unknown storage backend '%s', expected %s, %s or %s", backend,
+			structures.STORAGE_FIRESTORE, structures.STORAGE_MEMORY, structures.STORAGE_BOLT)
+	}
+}
+
+// opens the backend named by STORAGE_BACKEND, firestore if it has not been set
+func OpenFromEnv(ctx context.Context) (Store, error) {
+	return Open(ctx, getEnv("STORAGE_BACKEND", structures.STORAGE_FIRESTORE))
+}
+
+// returns the environment variable or the fallback if it is empty
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,333 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"groupXX/structures"
+)
+
+// every backend which doesn't need external services
+func testStores(t *testing.T) map[string]Store {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewBoltStore() returned an error: %v", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return map[string]Store{
+		structures.STORAGE_MEMORY: NewMemoryStore(),
+		structures.STORAGE_BOLT:   bolt,
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			webhook := structures.Webhook{URL: "http://localhost/hook", Country: "NOR", Calls: 2}
+
+			id, err := store.StoreWebhook(ctx, webhook)
+			if err != nil {
+				t.Fatalf("StoreWebhook() returned an error: %v", err)
+			}
+
+			stored, err := store.GetWebhook(ctx, id)
+			if err != nil {
+				t.Fatalf("GetWebhook() returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(stored, webhook) {
+				t.Errorf("Stored webhook %+v does not match original webhook %+v", stored, webhook)
+			}
+
+			all, err := store.GetAllWebhooks(ctx)
+			if err != nil {
+				t.Fatalf("GetAllWebhooks() returned an error: %v", err)
+			}
+			if len(all) != 1 || all[0].ID != id {
+				t.Errorf("GetAllWebhooks() returned %+v, expected only %s", all, id)
+			}
+
+			webhook.Secret = "rotated"
+			if err := store.UpdateWebhook(ctx, id, webhook); err != nil {
+				t.Fatalf("UpdateWebhook() returned an error: %v", err)
+			}
+			if stored, _ := store.GetWebhook(ctx, id); stored.Secret != "rotated" {
+				t.Errorf("UpdateWebhook() did not change the secret, got %+v", stored)
+			}
+			if err := store.UpdateWebhook(ctx, "unknown", webhook); err != structures.ErrWebhookNotFound {
+				t.Errorf("UpdateWebhook() on unknown id returned %v, expected %v", err, structures.ErrWebhookNotFound)
+			}
+
+			num, err := store.GetNumWebhooks(ctx)
+			if err != nil || num != 1 {
+				t.Errorf("GetNumWebhooks() returned %d, %v, expected 1", num, err)
+			}
+
+			if err := store.DeleteWebhook(ctx, id); err != nil {
+				t.Fatalf("DeleteWebhook() returned an error: %v", err)
+			}
+			if _, err := store.GetWebhook(ctx, id); err != structures.ErrWebhookNotFound {
+				t.Errorf("GetWebhook() after delete returned %v, expected %v", err, structures.ErrWebhookNotFound)
+			}
+			if err := store.DeleteWebhook(ctx, id); err != structures.ErrWebhookNotFound {
+				t.Errorf("DeleteWebhook() twice returned %v, expected %v", err, structures.ErrWebhookNotFound)
+			}
+		})
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i := 1; i <= structures.MAXDELIVERYRECORDS+2; i++ {
+				err := store.AddDelivery(ctx, structures.Delivery{WebhookID: "hook", Attempt: i})
+				if err != nil {
+					t.Fatalf("AddDelivery() returned an error: %v", err)
+				}
+			}
+
+			//only the latest are kept, oldest first
+			deliveries, err := store.GetDeliveries(ctx, "hook")
+			if err != nil {
+				t.Fatalf("GetDeliveries() returned an error: %v", err)
+			}
+			if len(deliveries) != structures.MAXDELIVERYRECORDS || deliveries[0].Attempt != 3 {
+				t.Errorf("GetDeliveries() returned %d records starting at attempt %d", len(deliveries), deliveries[0].Attempt)
+			}
+
+			deliveries, err = store.GetDeliveries(ctx, "other")
+			if err != nil || len(deliveries) != 0 {
+				t.Errorf("GetDeliveries() for unknown webhook returned %v, %v", deliveries, err)
+			}
+
+			id, err := store.AddDeadLetter(ctx, structures.DeadLetter{WebhookID: "hook", Payload: "{}"})
+			if err != nil {
+				t.Fatalf("AddDeadLetter() returned an error: %v", err)
+			}
+			letters, err := store.GetDeadLetters(ctx, "hook")
+			if err != nil || len(letters) != 1 || letters[0].ID != id {
+				t.Errorf("GetDeadLetters() returned %+v, %v", letters, err)
+			}
+			if err := store.DeleteDeadLetter(ctx, id); err != nil {
+				t.Errorf("DeleteDeadLetter() returned an error: %v", err)
+			}
+			if err := store.DeleteDeadLetter(ctx, id); err != structures.ErrDeadLetterNotFound {
+				t.Errorf("DeleteDeadLetter() twice returned %v, expected %v", err, structures.ErrDeadLetterNotFound)
+			}
+		})
+	}
+}
+
+func TestCalls(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			//increments from many goroutines at once must not be lost
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := store.IncrementCalls(ctx, "NOR"); err != nil {
+						t.Errorf("IncrementCalls() returned an error: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			count, err := store.IncrementCalls(ctx, "SWE")
+			if err != nil || count != 1 {
+				t.Errorf("IncrementCalls() returned %d, %v, expected 1", count, err)
+			}
+
+			calls, err := store.GetAllCalls(ctx)
+			if err != nil {
+				t.Fatalf("GetAllCalls() returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(calls, map[string]int64{"NOR": 50, "SWE": 1}) {
+				t.Errorf("GetAllCalls() returned %v", calls)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	data := []structures.DataEntry{{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 71.558365}}
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			cached, err := store.GetCachedData(ctx, "norway_true_<nil>_<nil>")
+			if err != nil || cached != nil {
+				t.Fatalf("GetCachedData() on empty cache returned %v, %v", cached, err)
+			}
+
+			if err := store.SetCachedData(ctx, "Norway_true_<nil>_<nil>", data); err != nil {
+				t.Fatalf("SetCachedData() returned an error: %v", err)
+			}
+
+			//keys are case insensitive like the firestore cache
+			cached, err = store.GetCachedData(ctx, "NORWAY_true_<nil>_<nil>")
+			if err != nil {
+				t.Fatalf("GetCachedData() returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(cached.Data, data) {
+				t.Errorf("GetCachedData() returned %+v, expected %+v", cached.Data, data)
+			}
+			//one hit from the set and one from the get
+			if cached.Hits != 2 {
+				t.Errorf("GetCachedData() returned %d hits, expected 2", cached.Hits)
+			}
+
+			//nothing is old enough to be purged
+			deleted, err := store.PurgeOldCacheEntries(ctx, structures.DAYSTHRESHOLD)
+			if err != nil || deleted != 0 {
+				t.Errorf("PurgeOldCacheEntries() returned %d, %v, expected 0", deleted, err)
+			}
+
+			//a negative threshold lies in the future, so everything is purged
+			deleted, err = store.PurgeOldCacheEntries(ctx, -1)
+			if err != nil || deleted != 1 {
+				t.Errorf("PurgeOldCacheEntries() returned %d, %v, expected 1", deleted, err)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open(context.Background(), structures.STORAGE_MEMORY)
+	if err != nil {
+		t.Fatalf("Open() returned an error: %v", err)
+	}
+	if _, ok := store.(*MemoryStore); !ok {
+		t.Errorf("Open(%q) returned %T", structures.STORAGE_MEMORY, store)
+	}
+
+	if _, err := Open(context.Background(), "postgres"); err == nil {
+		t.Errorf("Open() with unknown backend returned no error")
+	}
+}
+
+func TestPing(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Ping(ctx); err != nil {
+				t.Errorf("Ping() returned an error: %v", err)
+			}
+			if store.Backend() != name {
+				t.Errorf("Backend() returned %s, expected %s", store.Backend(), name)
+			}
+		})
+	}
+
+	//a closed file doesn't answer
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "closed.db"))
+	if err != nil {
+		t.Fatalf("NewBoltStore() returned an error: %v", err)
+	}
+	bolt.Close()
+	if err := bolt.Ping(ctx); err == nil {
+		t.Errorf("Ping() of a closed store returned no error")
+	}
+}
+
+func TestSetWebhookState(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			webhook := structures.Webhook{URL: "http://localhost/hook", Country: "NOR", Event: structures.EVENT_THRESHOLD,
+				Threshold: 70, Direction: structures.DIRECTION_ABOVE, Secret: "old"}
+			id, err := store.StoreWebhook(ctx, webhook)
+			if err != nil {
+				t.Fatalf("StoreWebhook() returned an error: %v", err)
+			}
+
+			//the secret changed after the state was read is kept
+			webhook.Secret = "new"
+			if err := store.UpdateWebhook(ctx, id, webhook); err != nil {
+				t.Fatalf("UpdateWebhook() returned an error: %v", err)
+			}
+			if err := store.SetWebhookState(ctx, id, structures.DIRECTION_ABOVE); err != nil {
+				t.Fatalf("SetWebhookState() returned an error: %v", err)
+			}
+			stored, err := store.GetWebhook(ctx, id)
+			if err != nil {
+				t.Fatalf("GetWebhook() returned an error: %v", err)
+			}
+			webhook.LastState = structures.DIRECTION_ABOVE
+			if !reflect.DeepEqual(stored, webhook) {
+				t.Errorf("Stored webhook %+v does not match %+v", stored, webhook)
+			}
+
+			if err := store.SetWebhookState(ctx, "missing", structures.DIRECTION_BELOW); err != structures.ErrWebhookNotFound {
+				t.Errorf("SetWebhookState() of a missing webhook returned %v, expected %v", err, structures.ErrWebhookNotFound)
+			}
+		})
+	}
+}
+
+func TestSetWebhookSecret(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			webhook := structures.Webhook{URL: "http://localhost/hook", Country: "NOR", Event: structures.EVENT_THRESHOLD,
+				Threshold: 70, Direction: structures.DIRECTION_ABOVE, Secret: "old"}
+			id, err := store.StoreWebhook(ctx, webhook)
+			if err != nil {
+				t.Fatalf("StoreWebhook() returned an error: %v", err)
+			}
+
+			//the state set after the webhook was read for the patch is kept
+			if err := store.SetWebhookState(ctx, id, structures.DIRECTION_ABOVE); err != nil {
+				t.Fatalf("SetWebhookState() returned an error: %v", err)
+			}
+			if err := store.SetWebhookSecret(ctx, id, "new"); err != nil {
+				t.Fatalf("SetWebhookSecret() returned an error: %v", err)
+			}
+			stored, err := store.GetWebhook(ctx, id)
+			if err != nil {
+				t.Fatalf("GetWebhook() returned an error: %v", err)
+			}
+			webhook.Secret, webhook.LastState = "new", structures.DIRECTION_ABOVE
+			if !reflect.DeepEqual(stored, webhook) {
+				t.Errorf("Stored webhook %+v does not match %+v", stored, webhook)
+			}
+
+			if err := store.SetWebhookSecret(ctx, "missing", "new"); err != structures.ErrWebhookNotFound {
+				t.Errorf("SetWebhookSecret() of a missing webhook returned %v, expected %v", err, structures.ErrWebhookNotFound)
+			}
+		})
+	}
+}
+
+func TestDeleteWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			id, err := store.StoreWebhook(ctx, structures.Webhook{URL: "http://localhost/hook", Country: "NOR", Calls: 2})
+			if err != nil {
+				t.Fatalf("StoreWebhook() returned an error: %v", err)
+			}
+			for i := 1; i <= 3; i++ {
+				if err := store.AddDelivery(ctx, structures.Delivery{WebhookID: id, Attempt: i}); err != nil {
+					t.Fatalf("AddDelivery() returned an error: %v", err)
+				}
+			}
+
+			//the delivery attempts go with the webhook
+			if err := store.DeleteWebhook(ctx, id); err != nil {
+				t.Fatalf("DeleteWebhook() returned an error: %v", err)
+			}
+			deliveries, err := store.GetDeliveries(ctx, id)
+			if err != nil || len(deliveries) != 0 {
+				t.Errorf("GetDeliveries() of a deleted webhook returned %v, %v", deliveries, err)
+			}
+		})
+	}
+}
@@ -1,18 +1,183 @@
 package structures 
 
+import (
+	"errors"
+	"time"
+)
+
 //consts for the different paths
 const DEFAULT_PATH = "/"
 const RENEWABLECURRENT_PATH = "/energy/v1/renewables/current/"
 const RENEWABLEHISTORY_PATH = "/energy/v1/renewables/history/"
 const NOTIFICATIONS_PATH = "/energy/v1/notifications/"
+const DELIVERIES_SUFFIX = "/deliveries"
 const STATUS_PATH = "/energy/v1/status/"
+const STATS_PATH = "/energy/v1/stats/"
+const SEARCH_PATH = "/energy/v1/countries/search"
+const RELOAD_PATH = "/energy/v1/admin/reload"
+const METRICS_PATH = "/energy/v1/metrics/"
+const MIX_PATH = "/energy/v1/mix/"
+const REGIONS_PATH = "/energy/v1/regions/"
+const AGGREGATE_PATH = "/energy/v1/aggregate/"
+const RANK_PATH = "/energy/v1/renewables/rank/"
+const TREND_PATH = "/energy/v1/renewables/trend/"
+const FORECAST_PATH = "/energy/v1/renewables/forecast/"
+const COMPARE_PATH = "/energy/v1/renewables/compare/"
 const INFO_PATH = "/energy/v1/info/"
 
 //consts for files and URL's
 const FILEPATH = "./structures/energyData.csv"
 const TESTCOUNTRYFILE = "./countriesData.json"
-const COUNTRYSEARCH = "http://129.241.150.113:8080/v3.1/name/"
+const COUNTRIESFILE = "./structures/countries.json"
+const REGIONSFILE = "./structures/regions.json"
+const COUNTRIESAPI = "http://129.241.150.113:8080/v3.1"
+
+//consts for the storage backends, chosen with the STORAGE_BACKEND environment variable
+const STORAGE_FIRESTORE = "firestore"
+const STORAGE_MEMORY = "memory"
+const STORAGE_BOLT = "bolt"
+
+//default locations for the storage backends, can be overridden with environment variables
+const FIRESTORE_PROJECT = "group66assignment2"
+const FIRESTORE_CREDENTIALS = "./.secrets/group66assignment2-firebase-adminsdk-pv6iv-c0b5b34aeb.json"
+const BOLTFILE = "./energy.db"
+
+//how long the status endpoint waits for the storage backend to answer
+const STORAGEPINGTIMEOUT = 5 * time.Second
+
+//consts for the events a webhook can be registered for
+const EVENT_CALLS = "calls"
+const EVENT_THRESHOLD = "threshold"
+const DIRECTION_ABOVE = "above"
+const DIRECTION_BELOW = "below"
+
+//consts for how a search matched a country, from the best to the worst match
+const MATCH_EXACT = "exact"
+const MATCH_PREFIX = "prefix"
+const MATCH_SUBSTRING = "substring"
+const MATCH_FUZZY = "fuzzy"
+
+//call counter key for searches without a country, and for webhooks registered on any country
+const ALLCOUNTRIES = "ALL"
+
+//the metric shown unless another one is asked for, the share of primary energy from renewables
+const DEFAULTMETRIC = "renewables_share_energy"
+
+//consts for the kinds of regions
+const REGION_WORLD = "world"
+const REGION_CONTINENT = "continent"
+const REGION_POLITICAL = "political"
+const REGION_HISTORICAL = "historical"
+const REGION_INCOME = "income"
+const REGION_SOURCE = "source"
+
+//name of an aggregate of countries given in the request instead of a region
+const CUSTOMREGION = "custom"
+
+//consts for what the current year is, the latest year of each country or the latest year of any country
+const LATEST_COUNTRY = "country"
+const LATEST_GLOBAL = "global"
+
+//consts for the models of forecasts
+const FORECAST_LINEAR = "linear"
+const FORECAST_HOLT = "holt"
+
+//consts for the order of rankings
+const ORDER_DESC = "desc"
+const ORDER_ASC = "asc"
+
+//consts for the formats of responses, asked for with ?format= or the Accept header
+const FORMAT_JSON = "json"
+const FORMAT_CSV = "csv"
+const FORMAT_NDJSON = "ndjson"
+const FORMAT_XLSX = "xlsx"
+const JSON_CONTENT_TYPE = "application/json"
+const CSV_CONTENT_TYPE = "text/csv"
+const NDJSON_CONTENT_TYPE = "application/x-ndjson"
+const XLSX_CONTENT_TYPE = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+//header of the renewables percentage in energyData.csv, used for it in csv and xlsx responses
+const RENEWABLESHEADER = "Renewables (% equivalent primary energy)"
 
 //consts for sizes
 const MAXCACHESIZE = 15
-const DAYSTHRESHOLD = 2
\ No newline at end of file
+const DAYSTHRESHOLD = 2
+const DEFAULTTOP = 10
+const MAXSEARCHRESULTS = 10
+const MAXSUGGESTIONS = 3
+const DEFAULTFORECASTYEAR = 2030
+const MAXFORECASTYEARS = 50
+const MAXCOMPARECOUNTRIES = 20
+const MAXNEIGHBOURDEPTH = 10
+const DEFAULTPAGELIMIT = 100
+const MAXPAGELIMIT = 1000
+//lines of a ndjson response between each flush
+const NDJSONFLUSHLINES = 500
+//z value of the 95% prediction intervals of forecasts
+const FORECASTZ = 1.96
+
+//how often the data file is checked for changes
+const DATAPOLLINTERVAL = 30 * time.Second
+
+//consts for reading the neighbours of a country, how many are read at once and how long a request may spend
+const NEIGHBOURWORKERS = 8
+const NEIGHBOURTIMEOUT = 5 * time.Second
+
+//consts for requests to the countries API, how long each may take, how often a failed one is tried again and
+//how long to wait before the first retry, doubled for each retry after
+const COUNTRIESTIMEOUT = 10 * time.Second
+const COUNTRIESRETRIES = 2
+const COUNTRIESBACKOFF = 200 * time.Millisecond
+const COUNTRIESUSERAGENT = "energy-service/1.0"
+
+//how long the status endpoint waits for the countries API before it reports it as unavailable
+const STATUSPROBETIMEOUT = 2 * time.Second
+
+//consts for the circuit breaker in front of the countries API, how many failures in a row open it and how long
+//it stays open before one request is let through to check if the API is back
+const BREAKERFAILURES = 5
+const BREAKERCOOLDOWN = 30 * time.Second
+
+//consts for the states of a circuit breaker
+const BREAKER_CLOSED = "closed"
+const BREAKER_OPEN = "open"
+const BREAKER_HALFOPEN = "half-open"
+
+//consts for the webhook deliveries
+const WEBHOOKWORKERS = 4
+const WEBHOOKQUEUESIZE = 100
+const WEBHOOKATTEMPTS = 5
+const WEBHOOKBACKOFF = time.Second
+const WEBHOOKMAXBACKOFF = time.Minute
+const WEBHOOKTIMEOUT = 10 * time.Second
+const MAXDELIVERYRECORDS = 100
+
+//content type of error responses, see RFC 7807
+const PROBLEM_CONTENT_TYPE = "application/problem+json"
+
+//stable codes of error responses, which clients can check instead of the message
+const ERR_INVALID_PARAMETER = "invalid_parameter"
+const ERR_MISSING_PARAMETER = "missing_parameter"
+const ERR_UNKNOWN_METRIC = "unknown_metric"
+const ERR_COUNTRY_NOT_FOUND = "country_not_found"
+const ERR_REGION_NOT_FOUND = "region_not_found"
+const ERR_WEBHOOK_NOT_FOUND = "webhook_not_found"
+const ERR_NOT_FOUND = "not_found"
+const ERR_INSUFFICIENT_DATA = "insufficient_data"
+const ERR_UNAUTHORIZED = "unauthorized"
+const ERR_FORBIDDEN = "forbidden"
+const ERR_METHOD_NOT_SUPPORTED = "method_not_supported"
+const ERR_NOT_ACCEPTABLE = "not_acceptable"
+const ERR_UPSTREAM = "upstream_error"
+const ERR_UNAVAILABLE = "unavailable"
+const ERR_INTERNAL = "internal_error"
+
+//errors shared by all the storage backends
+var ErrWebhookNotFound = errors.New("webhook not found")
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+//error of a countries client when no country matches
+var ErrCountryNotFound = errors.New("Country not found")
+
+//error of the countries client when the breaker is open and there is no earlier answer to serve
+var ErrBreakerOpen = errors.New("countries API is unavailable, circuit breaker is open")
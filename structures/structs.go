@@ -1,5 +1,7 @@
 package structures
 
+import "time"
+
 //data entry from the energyData.csv file
 type DataEntry struct {
 	Country     string  `json:"name"`
@@ -8,22 +10,260 @@ type DataEntry struct {
 	Percentage  float64 `json:"percentage"`
 }
 
-//countyr struct for the country collecting third party service
+//countyr struct for the country collecting third party service, also the format of the bundled countries.json
 type Country struct {
-	Borders     []string `json:"borders"`
-	CountryCode string   `json:"cca2"`
-	Name        struct {
-		Common string `json:"common"`
-	} `json:"name"`
+	Borders      []string    `json:"borders"`
+	CountryCode  string      `json:"cca2"`
+	Alpha3       string      `json:"cca3"`
+	Numeric      string      `json:"ccn3"`
+	Name         CountryName `json:"name"`
+	AltSpellings []string    `json:"altSpellings"`
+}
+
+//names of a country
+type CountryName struct {
+	Common   string `json:"common"`
+	Official string `json:"official"`
 }
 
 //to store information
 type Info struct {
 	RESTStatus  int     `json:"countries_api"`
 	NotifStatus int     `json:"notification_db"`
+	Storage     string  `json:"storage"`
 	Webhooks    int     `json:"webhooks"`
 	Version     string  `json:"version"`
 	Uptime      float64 `json:"uptime"`
+	Cache       CacheStats `json:"cache"`
+	CountriesBreaker BreakerStats `json:"countries_breaker"`
+}
+
+//number of invocations of one country
+type CountryCalls struct {
+	CountryCode string `json:"isoCode"`
+	Name        string `json:"name,omitempty"`
+	Calls       int64  `json:"calls"`
+}
+
+//response of the stats endpoint
+type Stats struct {
+	AllCountries int64          `json:"all_countries"`
+	Countries    []CountryCalls `json:"countries"`
+}
+
+//entry of another metric than the default, the value is in the unit of the metric
+type MetricEntry struct {
+	Country     string  `json:"name"`
+	CountryCode string  `json:"isoCode"`
+	Year        int     `json:"year"`
+	Metric      string  `json:"metric"`
+	Value       float64 `json:"value"`
+	//land borders from the country asked for, only when neighbours are asked for
+	Distance *int `json:"distance,omitempty"`
+}
+
+//share of primary energy from each source for a country and year
+type Mix struct {
+	Country     string             `json:"name"`
+	CountryCode string             `json:"isoCode"`
+	Year        int                `json:"year"`
+	Sources     map[string]float64 `json:"sources"`
+}
+
+//an aggregate in the data, like a continent or an income group, and the iso codes of the countries in it
+type Region struct {
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
+	Members []string `json:"members"`
+	//if the data has its own row for the region
+	InData bool `json:"inData"`
+}
+
+//value of a metric for a group of countries, computed by the service
+type AggregateEntry struct {
+	Name   string  `json:"name"`
+	Year   int     `json:"year"`
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	//metric the countries are weighted by, empty for a plain mean
+	Weight string `json:"weight,omitempty"`
+	//how many of the countries had a value for the year
+	Countries int `json:"countries"`
+	Members   int `json:"members"`
+}
+
+//position of a country in the ranking of a year
+type RankEntry struct {
+	Rank        int     `json:"rank"`
+	Country     string  `json:"name"`
+	CountryCode string  `json:"isoCode"`
+	Year        int     `json:"year"`
+	Percentage  float64 `json:"percentage"`
+	//share of the ranked countries with a lower percentage
+	Percentile float64 `json:"percentile"`
+	//rank in the comparison year and how many places the country has climbed since, absent if it wasn't ranked then
+	PreviousRank *int `json:"previousRank,omitempty"`
+	RankChange   *int `json:"rankChange,omitempty"`
+}
+
+//value of a metric in one year
+type YearValue struct {
+	Year  int     `json:"year"`
+	Value float64 `json:"value"`
+}
+
+//statistics of how a metric has changed for a country over a range of years
+type Trend struct {
+	Country     string    `json:"name"`
+	CountryCode string    `json:"isoCode"`
+	Metric      string    `json:"metric"`
+	Years       int       `json:"years"`
+	First       YearValue `json:"first"`
+	Last        YearValue `json:"last"`
+	Min         YearValue `json:"min"`
+	Max         YearValue `json:"max"`
+	//change from the first to the last year, in the unit of the metric and in percent of the first value
+	AbsoluteChange float64  `json:"absoluteChange"`
+	RelativeChange *float64 `json:"relativeChange"`
+	//compound annual growth rate in percent, absent if the first or last value isn't positive
+	CAGR *float64 `json:"cagr"`
+	//least squares line through the values, the slope is per year and R² is absent if every value is the same
+	Slope     float64  `json:"slope"`
+	Intercept float64  `json:"intercept"`
+	RSquared  *float64 `json:"rSquared"`
+	//standard deviation of the change per year
+	Volatility float64 `json:"volatility"`
+}
+
+//an observed year of a country, or a projected year with the 95% prediction interval
+type ForecastEntry struct {
+	DataEntry
+	Projected bool     `json:"projected"`
+	Lower     *float64 `json:"lower,omitempty"`
+	Upper     *float64 `json:"upper,omitempty"`
+}
+
+//series of several countries aligned by year
+type Comparison struct {
+	Metric string `json:"metric"`
+	//every year any of the countries has a value for, the values of each country are in the same order
+	Years []int `json:"years"`
+	//iso code of the country the differences are against
+	Baseline  string           `json:"baseline,omitempty"`
+	Countries []ComparedSeries `json:"countries"`
+}
+
+//values of one country in a comparison, null for the years the country has no value for
+type ComparedSeries struct {
+	Country     string     `json:"name"`
+	CountryCode string     `json:"isoCode"`
+	Values      []*float64 `json:"values"`
+	Missing     []int      `json:"missing"`
+	//value minus the value of the baseline country, null if either is missing
+	Difference []*float64 `json:"difference,omitempty"`
+}
+
+//a country within some land borders of another country
+type Neighbour struct {
+	Country  string `json:"name"`
+	Alpha3   string `json:"isoCode"`
+	Distance int    `json:"distance"`
+}
+
+//entry of the history of a country or one of its neighbours
+type NeighbourEntry struct {
+	DataEntry
+	Distance int `json:"distance"`
+}
+
+//a country and its neighbours, with the neighbours which couldn't be read in warnings
+type NeighbourResponse struct {
+	Entries  interface{} `json:"entries"`
+	Warnings []string    `json:"warnings"`
+}
+
+//how many years an entry of the current endpoint is behind the latest year in the data
+type Staleness struct {
+	LatestYear  int  `json:"latestYear"`
+	YearsBehind int  `json:"yearsBehind"`
+	Stale       bool `json:"stale"`
+}
+
+//entries of the current endpoint
+type CurrentEntry struct {
+	DataEntry
+	Staleness
+	//land borders from the country asked for, only when neighbours are asked for
+	Distance *int `json:"distance,omitempty"`
+}
+
+type CurrentMetricEntry struct {
+	MetricEntry
+	Staleness
+}
+
+//a country found by the search endpoint, with how well it matched
+type SearchResult struct {
+	Name        string `json:"name"`
+	CountryCode string `json:"isoCode"`
+	Match       string `json:"match"`
+	Distance    int    `json:"distance"`
+}
+
+//response of a reload of the data
+type ReloadResult struct {
+	Entries   int    `json:"entries"`
+	Countries int    `json:"countries"`
+	Version   string `json:"version"`
+}
+
+//body of a list response asked for with ?envelope=true, the entries and what they are
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta Meta        `json:"meta"`
+}
+
+//what the entries of an envelope are, the page fields are only there when the list is paginated
+type Meta struct {
+	Count       int       `json:"count"`
+	Total       int       `json:"total"`
+	Source      string    `json:"source"`
+	Version     string    `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Page        int       `json:"page,omitempty"`
+	Limit       int       `json:"limit,omitempty"`
+	Pages       int       `json:"pages,omitempty"`
+}
+
+//error of a request, with the status and a stable code, written to the user as a Problem
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Details interface{}
+	//methods for the Allow header of a 405, empty for other errors
+	Allow string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+//body of an error response as described in RFC 7807, with the code and details of the error as extension members
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+//details of a 404 response, with the closest countries to what was searched for
+type NotFoundDetails struct {
+	Search      string   `json:"search"`
+	Suggestions []string `json:"did_you_mean"`
 }
 
 //content of a webhook
@@ -31,6 +271,35 @@ type Webhook struct {
 	URL     string `json:"url"`
 	Country string `json:"country"`
 	Calls   int    `json:"calls"`
+	//used to sign the deliveries, never written back to the users
+	Secret string `json:"secret,omitempty"`
+	//calls (default) or threshold
+	Event string `json:"event,omitempty"`
+	//for threshold webhooks, fires when the renewable percentage crosses the threshold in the direction
+	Threshold    float64 `json:"threshold,omitempty"`
+	Direction    string  `json:"direction,omitempty"`
+	YearOverYear bool    `json:"year_over_year,omitempty"`
+	//above or below, the side of the threshold the country was on when the data was last checked
+	LastState string `json:"last_state,omitempty"`
+}
+
+//payload of a threshold webhook
+type ThresholdEvent struct {
+	WebhookID    string  `json:"webhook_id"`
+	Event        string  `json:"event"`
+	Country      string  `json:"country"`
+	Threshold    float64 `json:"threshold"`
+	Direction    string  `json:"direction"`
+	YearOverYear bool    `json:"year_over_year"`
+	//the percentage, or the change in percentage points for year over year webhooks
+	Value    float64    `json:"value"`
+	Entry    DataEntry  `json:"entry"`
+	Previous *DataEntry `json:"previous_entry,omitempty"`
+}
+
+//fields of a webhook which can be changed after registration
+type WebhookPatch struct {
+	Secret *string `json:"secret"`
 }
 
 //id given to each webhook
@@ -39,10 +308,65 @@ type WebhookRegistration struct {
 	Webhook Webhook
 }
 
-//structure of the binary search three
-type BSTNode struct {
-	Data   []DataEntry
-	Letter rune
-	Left   *BSTNode
-	Right  *BSTNode
+//one attempt at delivering a webhook invocation
+type Delivery struct {
+	WebhookID  string    `json:"webhook_id"`
+	DeliveryID string    `json:"delivery_id"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	LatencyMs  float64   `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+//an invocation which failed on every attempt, kept so it can be replayed
+type DeadLetter struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	DeliveryID string    `json:"delivery_id"`
+	Payload    string    `json:"payload"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error"`
+	Time       time.Time `json:"time"`
+}
+
+//response of the deliveries endpoint
+type DeliveryLog struct {
+	Deliveries  []Delivery   `json:"deliveries"`
+	DeadLetters []DeadLetter `json:"dead_letters"`
+}
+
+//a cached search result together with its hit count
+type CacheEntry struct {
+	Key       string      `json:"key"`
+	Data      []DataEntry `json:"data"`
+	Timestamp time.Time   `json:"timestamp"`
+	Hits      int64       `json:"hits"`
+}
+
+//hit and miss counters for one tier of the search cache
+type CacheTierStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+//state of the circuit breaker in front of the countries API
+type BreakerStats struct {
+	State string `json:"state"`
+	//failures in a row, the breaker opens when they reach the limit
+	Failures int `json:"failures"`
+	//when the breaker last opened, and the last error from the API
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	//answers served from the last known good answers because the API failed or the breaker was open
+	StaleServed int64 `json:"stale_served"`
+}
+
+//counters for the whole search cache
+type CacheStats struct {
+	Size      int            `json:"size"`
+	MaxSize   int            `json:"max_size"`
+	Evictions int64          `json:"evictions"`
+	Memory    CacheTierStats `json:"memory"`
+	Store     CacheTierStats `json:"store"`
 }
@@ -0,0 +1,216 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"groupXX/signature"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+// Job is one invocation of a webhook, retried until it succeeds or runs out of attempts
+type Job struct {
+	WebhookID  string
+	DeliveryID string
+	URL        string
+	Payload    []byte
+	//signs every attempt if not empty
+	Secret  string
+	attempt int
+}
+
+// Dispatcher delivers webhook invocations in the background with a bounded number of workers
+type Dispatcher struct {
+	queue  chan Job
+	done   chan struct{}
+	wg     sync.WaitGroup
+	client *http.Client
+
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// the dispatcher used by the service
+var Default = NewDispatcher(structures.WEBHOOKWORKERS, structures.WEBHOOKQUEUESIZE)
+
+// creates a dispatcher and starts its workers
+func NewDispatcher(workers int, queueSize int) *Dispatcher {
+	d := &Dispatcher{
+		queue:       make(chan Job, queueSize),
+		done:        make(chan struct{}),
+		client:      &http.Client{Timeout: structures.WEBHOOKTIMEOUT},
+		MaxAttempts: structures.WEBHOOKATTEMPTS,
+		Backoff:     structures.WEBHOOKBACKOFF,
+		MaxBackoff:  structures.WEBHOOKMAXBACKOFF,
+	}
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// stops the workers, retries which are waiting for their backoff are dropped
+func (d *Dispatcher) Stop() {
+	close(d.done)
+	d.wg.Wait()
+}
+
+// queues a new invocation of the webhook without waiting for it, if the queue is full it goes straight to the dead letters
+func (d *Dispatcher) Enqueue(webhookID string, wh structures.Webhook, payload []byte) {
+	job := Job{WebhookID: webhookID, DeliveryID: uuid.NewString(), URL: wh.URL, Payload: payload, Secret: wh.Secret, attempt: 1}
+	select {
+	case d.queue <- job:
+	default:
+		//never attempted
+		job.attempt = 0
+		d.deadLetter(job, "delivery queue is full")
+	}
+}
+
+// queues every dead letter of the webhook again with a fresh set of attempts, returns how many were queued
+func (d *Dispatcher) Replay(ctx context.Context, webhookID string) (int, error) {
+	wh, err := storage.DB.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return 0, err
+	}
+	letters, err := storage.DB.GetDeadLetters(ctx, webhookID)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, letter := range letters {
+		//deleted first so the letter isn't replayed twice if replay is called again
+		err = storage.DB.DeleteDeadLetter(ctx, letter.ID)
+		if err == structures.ErrDeadLetterNotFound {
+			continue
+		} else if err != nil {
+			return replayed, err
+		}
+		//the current url and secret are used in case the subscriber has moved or rotated its secret
+		d.Enqueue(webhookID, wh, []byte(letter.Payload))
+		replayed++
+	}
+	return replayed, nil
+}
+
+// takes jobs from the queue until the dispatcher is stopped
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case job := <-d.queue:
+			d.deliver(job)
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// makes one attempt, records it and schedules a retry or dead letter on failure
+func (d *Dispatcher) deliver(job Job) {
+	delivery := structures.Delivery{
+		WebhookID:  job.WebhookID,
+		DeliveryID: job.DeliveryID,
+		Attempt:    job.attempt,
+		Time:       time.Now(),
+	}
+
+	statusCode, err := d.post(job)
+	delivery.LatencyMs = float64(time.Since(delivery.Time)) / float64(time.Millisecond)
+	delivery.StatusCode = statusCode
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	if err := storage.DB.AddDelivery(context.Background(), delivery); err != nil {
+		log.Printf("Error recording webhook delivery: %v", err)
+	}
+
+	if delivery.Error == "" {
+		return
+	}
+	if job.attempt >= d.MaxAttempts {
+		d.deadLetter(job, delivery.Error)
+		return
+	}
+
+	//waits in a timer instead of in the worker, so a subscriber which is down doesn't block the others
+	backoff := d.backoff(job.attempt)
+	job.attempt++
+	time.AfterFunc(backoff, func() {
+		select {
+		case d.queue <- job:
+		case <-d.done:
+		}
+	})
+}
+
+// posts the payload, anything but a 2xx status is an error
+func (d *Dispatcher) post(job Job) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	//signed per attempt so the timestamp is always fresh
+	timestamp := time.Now().Unix()
+	req.Header.Set(signature.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if job.Secret != "" {
+		req.Header.Set(signature.HeaderSignature, signature.Sign(job.Secret, timestamp, job.Payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	//reads the rest of the body so the connection can be reused
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("Subscriber returned status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// exponential backoff, Backoff after the first attempt and doubling up to MaxBackoff
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.Backoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// stores a job which won't be attempted again
+func (d *Dispatcher) deadLetter(job Job, lastError string) {
+	letter := structures.DeadLetter{
+		WebhookID:  job.WebhookID,
+		DeliveryID: job.DeliveryID,
+		Payload:    string(job.Payload),
+		Attempts:   job.attempt,
+		LastError:  lastError,
+		Time:       time.Now(),
+	}
+	if _, err := storage.DB.AddDeadLetter(context.Background(), letter); err != nil {
+		log.Printf("Error storing dead letter for webhook %s: %v", job.WebhookID, err)
+	}
+}
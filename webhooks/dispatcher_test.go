@@ -0,0 +1,159 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/signature"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+// a dispatcher with short backoffs so the tests don't have to wait
+func testDispatcher(t *testing.T) *Dispatcher {
+	storage.DB = storage.NewMemoryStore()
+	d := NewDispatcher(2, 10)
+	d.MaxAttempts = 3
+	d.Backoff = time.Millisecond
+	t.Cleanup(d.Stop)
+	return d
+}
+
+// waits until the condition is true or fails the test after a second
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDeliverySucceeds(t *testing.T) {
+	d := testDispatcher(t)
+	ctx := context.Background()
+
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = make([]byte, r.ContentLength)
+		r.Body.Read(body)
+	}))
+	defer ts.Close()
+
+	d.Enqueue("hook", structures.Webhook{URL: ts.URL}, []byte(`{"country":"NOR"}`))
+
+	waitFor(t, func() bool {
+		deliveries, _ := storage.DB.GetDeliveries(ctx, "hook")
+		return len(deliveries) == 1
+	})
+	deliveries, _ := storage.DB.GetDeliveries(ctx, "hook")
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.Equal(t, 1, deliveries[0].Attempt)
+	assert.Empty(t, deliveries[0].Error)
+	assert.Equal(t, `{"country":"NOR"}`, string(body))
+}
+
+func TestDeliveryRetriesThenDeadLetters(t *testing.T) {
+	d := testDispatcher(t)
+	ctx := context.Background()
+
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	d.Enqueue("hook", structures.Webhook{URL: ts.URL}, []byte(`{}`))
+
+	waitFor(t, func() bool {
+		letters, _ := storage.DB.GetDeadLetters(ctx, "hook")
+		return len(letters) == 1
+	})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	deliveries, _ := storage.DB.GetDeliveries(ctx, "hook")
+	assert.Len(t, deliveries, 3)
+	for i, delivery := range deliveries {
+		assert.Equal(t, i+1, delivery.Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, delivery.StatusCode)
+		assert.Equal(t, deliveries[0].DeliveryID, delivery.DeliveryID)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	d := testDispatcher(t)
+	ctx := context.Background()
+
+	var up int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&up) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	wh := structures.Webhook{URL: ts.URL, Country: "NOR", Calls: 1}
+	id, _ := storage.DB.StoreWebhook(ctx, wh)
+	d.Enqueue(id, wh, []byte(`{}`))
+	waitFor(t, func() bool {
+		letters, _ := storage.DB.GetDeadLetters(ctx, id)
+		return len(letters) == 1
+	})
+
+	//the subscriber comes back up and the dead letter is replayed
+	atomic.StoreInt32(&up, 1)
+	replayed, err := d.Replay(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+
+	waitFor(t, func() bool {
+		deliveries, _ := storage.DB.GetDeliveries(ctx, id)
+		return len(deliveries) == 4 && deliveries[3].StatusCode == http.StatusOK
+	})
+	letters, _ := storage.DB.GetDeadLetters(ctx, id)
+	assert.Empty(t, letters)
+
+	_, err = d.Replay(ctx, "unknown")
+	assert.Equal(t, structures.ErrWebhookNotFound, err)
+}
+
+func TestDeliveryIsSigned(t *testing.T) {
+	d := testDispatcher(t)
+	ctx := context.Background()
+
+	verified := make(chan error, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := signature.VerifyRequest(r, "secret", time.Minute)
+		verified <- err
+	}))
+	defer ts.Close()
+
+	d.Enqueue("hook", structures.Webhook{URL: ts.URL, Secret: "secret"}, []byte(`{"country":"NOR"}`))
+
+	select {
+	case err := <-verified:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatalf("Webhook was not delivered")
+	}
+	waitFor(t, func() bool {
+		deliveries, _ := storage.DB.GetDeliveries(ctx, "hook")
+		return len(deliveries) == 1
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+}
@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"groupXX/countries"
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+// returns the latest entry of the country and the one before it, nil if the country isn't in the data
+func latestEntries(country string, data []structures.DataEntry) (*structures.DataEntry, *structures.DataEntry) {
+	var latest, previous *structures.DataEntry
+	code := countries.ISO3(country)
+	for i := range data {
+		entry := &data[i]
+		//countries are matched on their iso code, aggregates like Europe on their name
+		if code != "" && entry.CountryCode != code {
+			continue
+		}
+		if code == "" && !strings.EqualFold(entry.Country, country) && !strings.EqualFold(entry.CountryCode, country) {
+			continue
+		}
+		if latest == nil || entry.Year > latest.Year {
+			previous = latest
+			latest = entry
+		} else if previous == nil || entry.Year > previous.Year {
+			previous = entry
+		}
+	}
+	return latest, previous
+}
+
+// evaluates a threshold webhook against the data, returning the state and what it was based on.
+// the state is empty if it can't be evaluated, like for unknown countries or year over year with only one year
+func evaluate(wh structures.Webhook, data []structures.DataEntry) (string, float64, *structures.DataEntry, *structures.DataEntry) {
+	latest, previous := latestEntries(wh.Country, data)
+	if latest == nil || (wh.YearOverYear && previous == nil) {
+		return "", 0, nil, nil
+	}
+
+	value := latest.Percentage
+	if wh.YearOverYear {
+		value = latest.Percentage - previous.Percentage
+	} else {
+		previous = nil
+	}
+
+	if value > wh.Threshold {
+		return structures.DIRECTION_ABOVE, value, latest, previous
+	}
+	return structures.DIRECTION_BELOW, value, latest, previous
+}
+
+// returns the side of the threshold the webhooks country is on, used when it is registered so it only fires on changes
+func ThresholdState(wh structures.Webhook, data []structures.DataEntry) string {
+	state, _, _, _ := evaluate(wh, data)
+	return state
+}
+
+// checks every threshold webhook against newly loaded data, and queues those whose country has crossed
+// the threshold in their direction since the last time. returns how many were queued
+func (d *Dispatcher) CheckThresholds(ctx context.Context, data []structures.DataEntry) (int, error) {
+	registrations, err := storage.DB.GetAllWebhooks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, registration := range registrations {
+		wh := registration.Webhook
+		if wh.Event != structures.EVENT_THRESHOLD {
+			continue
+		}
+
+		state, value, latest, previous := evaluate(wh, data)
+		if state == "" || state == wh.LastState {
+			continue
+		}
+
+		//a crossing only fires if it is in the registered direction, and never the first time it is evaluated
+		if wh.LastState != "" && state == wh.Direction {
+			event := structures.ThresholdEvent{
+				WebhookID:    registration.ID,
+				Event:        structures.EVENT_THRESHOLD,
+				Country:      wh.Country,
+				Threshold:    wh.Threshold,
+				Direction:    wh.Direction,
+				YearOverYear: wh.YearOverYear,
+				Value:        value,
+				Entry:        *latest,
+				Previous:     previous,
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return queued, err
+			}
+			d.Enqueue(registration.ID, wh, payload)
+			queued++
+		}
+
+		//only the state is written, so a secret or url changed since the webhooks were read is kept
+		if err := storage.DB.SetWebhookState(ctx, registration.ID, state); err != nil && err != structures.ErrWebhookNotFound {
+			log.Printf("Error storing threshold state of webhook %s: %v", registration.ID, err)
+		}
+	}
+	return queued, nil
+}
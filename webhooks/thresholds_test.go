@@ -0,0 +1,94 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"groupXX/storage"
+	"groupXX/structures"
+)
+
+var before = []structures.DataEntry{
+	{Country: "Norway", CountryCode: "NOR", Year: 2019, Percentage: 45},
+	{Country: "Norway", CountryCode: "NOR", Year: 2020, Percentage: 48},
+	{Country: "Sweden", CountryCode: "SWE", Year: 2020, Percentage: 50},
+}
+
+// the reloaded data, norway goes from 48 to 55
+var after = append(append([]structures.DataEntry{}, before...),
+	structures.DataEntry{Country: "Norway", CountryCode: "NOR", Year: 2021, Percentage: 55})
+
+func TestThresholdState(t *testing.T) {
+	wh := structures.Webhook{Country: "nor", Event: structures.EVENT_THRESHOLD, Threshold: 50}
+	assert.Equal(t, structures.DIRECTION_BELOW, ThresholdState(wh, before))
+	assert.Equal(t, structures.DIRECTION_ABOVE, ThresholdState(wh, after))
+
+	//year over year compares the change with the year before
+	wh.YearOverYear = true
+	wh.Threshold = 5
+	assert.Equal(t, structures.DIRECTION_BELOW, ThresholdState(wh, before))
+	assert.Equal(t, structures.DIRECTION_ABOVE, ThresholdState(wh, after))
+
+	//not enough data to compare with
+	wh.Country = "Sweden"
+	assert.Equal(t, "", ThresholdState(wh, after))
+	wh.Country = "Atlantis"
+	assert.Equal(t, "", ThresholdState(wh, after))
+}
+
+func TestCheckThresholds(t *testing.T) {
+	d := testDispatcher(t)
+	ctx := context.Background()
+
+	received := make(chan structures.ThresholdEvent, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := structures.ThresholdEvent{}
+		json.NewDecoder(r.Body).Decode(&event)
+		received <- event
+	}))
+	defer ts.Close()
+
+	above := structures.Webhook{URL: ts.URL, Country: "Norway", Event: structures.EVENT_THRESHOLD,
+		Threshold: 50, Direction: structures.DIRECTION_ABOVE}
+	above.LastState = ThresholdState(above, before)
+	aboveID, _ := storage.DB.StoreWebhook(ctx, above)
+
+	below := above
+	below.Direction = structures.DIRECTION_BELOW
+	belowID, _ := storage.DB.StoreWebhook(ctx, below)
+
+	//nothing has changed yet
+	queued, err := d.CheckThresholds(ctx, before)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, queued)
+
+	//norway crosses 50, only the webhook watching upwards fires
+	queued, err = d.CheckThresholds(ctx, after)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, queued)
+
+	select {
+	case event := <-received:
+		assert.Equal(t, aboveID, event.WebhookID)
+		assert.Equal(t, structures.EVENT_THRESHOLD, event.Event)
+		assert.Equal(t, 55.0, event.Value)
+		assert.Equal(t, after[3], event.Entry)
+		assert.Nil(t, event.Previous)
+	case <-time.After(time.Second):
+		t.Fatalf("Threshold webhook was not delivered")
+	}
+
+	//both remember the new state, so loading the same data again doesn't fire
+	for _, id := range []string{aboveID, belowID} {
+		wh, _ := storage.DB.GetWebhook(ctx, id)
+		assert.Equal(t, structures.DIRECTION_ABOVE, wh.LastState)
+	}
+	queued, _ = d.CheckThresholds(ctx, after)
+	assert.Equal(t, 0, queued)
+}